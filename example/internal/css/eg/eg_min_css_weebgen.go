@@ -1,19 +1,27 @@
 // autogenerated: do not edit!
-// generated from weebgen internal/css/eg/eg.min.css
+// generated from weebgen -embed -no-inline-data -url /css/eg.min.css internal/css/eg/eg.min.css
 
+//go:build !js
 // +build !js
 
 package eg_min_css
 
-import "github.com/platinasystems/weeb"
+import (
+	"embed"
+
+	"github.com/platinasystems/weeb"
+)
+
+//go:embed eg_min_css.gz
+var eg_min_css_fs embed.FS
 
 func init() {
 	c := &weeb.Content{
-		URLPath:              "/css/eg.min.css",
-		UnixTimeLastModified: 1447625089, // 2015-11-15 22:04:49.948173836 +0000 UTC
-		ContentType:          "text/css",
-		ContentEncoding:      "gzip",
-		FilePath:             "internal/css/eg/eg_min_css.gz",
+		URLPath:         "/css/eg.min.css",
+		ContentType:     "text/css",
+		ContentEncoding: "gzip",
+		FS:              eg_min_css_fs,
+		FilePath:        "eg_min_css.gz",
 	}
 	c.Register()
 }
@@ -1,19 +1,27 @@
 // autogenerated: do not edit!
-// generated from weebgen internal/js/foundation/foundation.min.js
+// generated from weebgen -embed -no-inline-data -url /js/foundation.min.js internal/js/foundation/foundation.min.js
 
+//go:build !js
 // +build !js
 
 package foundation_min_js
 
-import "github.com/platinasystems/weeb"
+import (
+	"embed"
+
+	"github.com/platinasystems/weeb"
+)
+
+//go:embed foundation_min_js.gz
+var foundation_min_js_fs embed.FS
 
 func init() {
 	c := &weeb.Content{
-		URLPath:              "/js/foundation.min.js",
-		UnixTimeLastModified: 1447625089, // 2015-11-15 22:04:49.934221149 +0000 UTC
-		ContentType:          "text/javascript",
-		ContentEncoding:      "gzip",
-		FilePath:             "internal/js/foundation/foundation_min_js.gz",
+		URLPath:         "/js/foundation.min.js",
+		ContentType:     "text/javascript",
+		ContentEncoding: "gzip",
+		FS:              foundation_min_js_fs,
+		FilePath:        "foundation_min_js.gz",
 	}
 	c.Register()
 }
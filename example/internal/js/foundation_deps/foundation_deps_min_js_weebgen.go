@@ -1,19 +1,27 @@
 // autogenerated: do not edit!
-// generated from weebgen internal/js/foundation_deps/foundation_deps.min.js
+// generated from weebgen -embed -no-inline-data -url /js/foundation_deps.min.js internal/js/foundation_deps/foundation_deps.min.js
 
+//go:build !js
 // +build !js
 
 package foundation_deps_min_js
 
-import "github.com/platinasystems/weeb"
+import (
+	"embed"
+
+	"github.com/platinasystems/weeb"
+)
+
+//go:embed foundation_deps_min_js.gz
+var foundation_deps_min_js_fs embed.FS
 
 func init() {
 	c := &weeb.Content{
-		URLPath:              "/js/foundation_deps.min.js",
-		UnixTimeLastModified: 1447625089, // 2015-11-15 22:04:49.917764511 +0000 UTC
-		ContentType:          "text/javascript",
-		ContentEncoding:      "gzip",
-		FilePath:             "internal/js/foundation_deps/foundation_deps_min_js.gz",
+		URLPath:         "/js/foundation_deps.min.js",
+		ContentType:     "text/javascript",
+		ContentEncoding: "gzip",
+		FS:              foundation_deps_min_js_fs,
+		FilePath:        "foundation_deps_min_js.gz",
 	}
 	c.Register()
 }
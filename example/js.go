@@ -8,12 +8,13 @@ import (
 	"github.com/gopherjs/websocket"
 	"github.com/platinasystems/weeb"
 	"github.com/platinasystems/weeb/canvas"
+	"github.com/platinasystems/weeb/form"
 	. "github.com/platinasystems/weeb/html"
 	"github.com/platinasystems/weeb/r2"
 
 	"fmt"
-	ghtml "html"
 	"log"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -26,10 +27,32 @@ var location = document.Get("location")
 var screenPixelsPerPoint float64
 
 var currentPath = location.Get("pathname").String()
-var doc = mySite.DocByPath[currentPath]
-var page = mySite.PageByPath[currentPath]
+var page, doc = newDoc(currentPath)
 var rpc *weeb.Rpc
 
+// Quoted CSS string for s (e.g. attribute selector value) escaping quotes, backslashes and control characters.
+func cssString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case 0:
+			b.WriteRune('\uFFFD')
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, "\\%x ", r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
 func submitInput(input jquery.JQuery) {
 	if currentPath != "/exec" {
 		log.Println("ignore non exec ", currentPath)
@@ -42,8 +65,7 @@ func submitInput(input jquery.JQuery) {
 	}
 
 	go func() {
-		d := mySite.DocByPath[currentPath]
-		page := mySite.PageByPath[currentPath]
+		d := doc
 
 		var pre, result string
 		args := strings.Split(cmd, " ")
@@ -53,7 +75,6 @@ func submitInput(input jquery.JQuery) {
 		} else {
 			pre = result
 		}
-		pre = ghtml.EscapeString(pre)
 		body := d.Pre(&String{pre}).Markup(d)
 
 		jq(input).SetAttr("disabled", "yes")
@@ -74,6 +95,15 @@ func submitInput(input jquery.JQuery) {
 	}()
 }
 
+// Page matching path and a new document rendered for path.
+func newDoc(path string) (p weeb.Page, d *Doc) {
+	p, params, _ := mySite.MatchParams(path)
+	if p != nil {
+		d = mySite.NewDoc(p, path, params)
+	}
+	return
+}
+
 func jqBind(j jquery.JQuery) {
 	jq(j).Find("[replace]").On(jquery.CLICK, func(e jquery.Event) {
 		replace(jq(e.Target))
@@ -81,11 +111,31 @@ func jqBind(j jquery.JQuery) {
 	})
 
 	jq(j).Find(".submit_on_click").On(jquery.CLICK, func(e jquery.Event) {
-		form := jq(e.Target).Closest("form")
-		form.Find(":input").Each(func(index int, x interface{}) {
+		f := jq(e.Target).Closest("form")
+		values := url.Values{}
+		f.Find(":input").Each(func(index int, x interface{}) {
 			i := jq(x)
-			log.Printf("%s: %s", i.Attr("id"), i.Val())
+			name := i.Attr("name")
+			if name == "" || i.Attr("type") == "checkbox" && !i.Is(":checked") {
+				return
+			}
+			values.Add(name, i.Val())
 		})
+		go func() {
+			var errs form.Errors
+			if err := rpc.Call("Listener.SubmitT", values, &errs); err != nil {
+				log.Printf("SubmitT: %v", err)
+				return
+			}
+			// Mark fields with errors.
+			f.Find("small.error").Remove()
+			f.Find(".error").RemoveClass("error")
+			for name, msg := range errs {
+				i := f.Find("[name=" + cssString(name) + "]")
+				i.AddClass("error")
+				i.After(doc.Small(".error", &String{X: msg}).Markup(doc))
+			}
+		}()
 		e.PreventDefault()
 	})
 
@@ -120,13 +170,14 @@ func replace(t jquery.JQuery) {
 		return
 	}
 
-	if page, doc, _ := mySite.Match(href); page == nil {
+	if p, d := newDoc(href); p == nil {
 		log.Printf("page not found %s", href)
 	} else {
 		idSelector := "#" + id
 		elt := jq(idSelector)
 		if elt.Length > 0 {
 			currentPath = href
+			page, doc = p, d
 
 			bn := doc.BodyNodeById[id]
 			elt.ReplaceWith(bn.Markup(doc))
@@ -163,7 +214,6 @@ func canvasContext(c jquery.JQuery) (x *canvas.Context) {
 
 func getDrawerListener(c jquery.JQuery) (d canvas.Drawer, l canvas.Listener) {
 	id := c.Attr("id")
-	page := mySite.PageByPath[currentPath]
 	switch p := page.(type) {
 	case canvas.Interface:
 		var ok bool
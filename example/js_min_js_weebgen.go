@@ -1,19 +1,27 @@
 // autogenerated: do not edit!
-// generated from weebgen js.min.js
+// generated from weebgen -embed -no-inline-data -package main -url /js/js.min.js js.min.js
 
+//go:build !js
 // +build !js
 
 package main
 
-import "github.com/platinasystems/weeb"
+import (
+	"embed"
+
+	"github.com/platinasystems/weeb"
+)
+
+//go:embed js_min_js.gz
+var js_min_js_fs embed.FS
 
 func init() {
 	c := &weeb.Content{
-		URLPath:              "/js/js.min.js",
-		UnixTimeLastModified: 1447625090, // 2015-11-15 22:04:50.380138776 +0000 UTC
-		ContentType:          "text/javascript",
-		ContentEncoding:      "gzip",
-		FilePath:             "./js_min_js.gz",
+		URLPath:         "/js/js.min.js",
+		ContentType:     "text/javascript",
+		ContentEncoding: "gzip",
+		FS:              js_min_js_fs,
+		FilePath:        "js_min_js.gz",
 	}
 	c.Register()
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/platinasystems/elib/elog"
 	"github.com/platinasystems/weeb"
 	"github.com/platinasystems/weeb/canvas"
+	"github.com/platinasystems/weeb/form"
 	. "github.com/platinasystems/weeb/html"
 	"github.com/platinasystems/weeb/r2"
 )
@@ -20,7 +21,7 @@ type ExecCommand struct {
 func (c *ExecCommand) Body(d *Doc) BodyVec {
 	i := d.Input("type=text")
 	if len(c.Prompt) > 0 {
-		i.Attrs.Set(i, d, "placeholder="+c.Prompt)
+		i.Placeholder = c.Prompt
 	}
 	return BodyVec{
 		d.Div(".ExecCommand",
@@ -41,23 +42,18 @@ func initSite() *weeb.Site {
 		PageByPath: map[string]weeb.Page{
 			"/":       &rootPage{},
 			"/page/":  &pathPage{},
-			"/exec":   &execPage{},
-			"/canvas": &myCanvasPage{},
-			"/elog":   &myElogPage{},
+			"/exec":   &execPage{ExecCommand{Prompt: "Enter Command"}},
+			"/canvas": newCanvasPage(),
+			"/elog":   newElogPage(),
+		},
+		Head: head,
+		RpcReceivers: func(r *weeb.Rpc) []interface{} {
+			return []interface{}{&Listener{rpc: r}}
 		},
 	}
-
-	s.DocByPath = make(map[string]*Doc)
-	for path, p := range s.PageByPath {
-		d := &Doc{
-			Head: head,
-		}
-
-		d.Body = p.PageBody(path, d)
-
-		s.DocByPath[path] = d
+	if err := s.InitPatterns(); err != nil {
+		panic(err)
 	}
-
 	return s
 }
 
@@ -133,56 +129,12 @@ func (s *standardBody) Body(d *Doc) BodyVec {
 	}
 }
 
-type inlineLabel struct {
-	Id   string
-	Type InputType
-}
-
-func (t *inlineLabel) Body(d *Doc) BodyVec {
-	p := t.Type
-	if p == InputType(0) {
-		p = Text
-	}
-	i := d.Input("#" + t.Id)
-	i.InputType = p
-	return BodyVec{
-		d.Div(".row",
-			d.Div(".large-3 columns", d.Label(".right inline", "for="+t.Id, t.Id)),
-			d.Div(".large-9 columns", i)),
-	}
-}
-
+// Form generated from struct fields; see package form for tags.
 type T struct {
-	A int
-	B int
-	C string
-	D float64
-}
-
-func (t *T) Body(d *Doc) BodyVec {
-	if false {
-		return BodyVec{
-			d.Div(".row",
-				d.Div(".large-4 columns", d.Label("A", d.Input("#A", "type=number"))),
-				d.Div(".large-4 columns", d.Label("B", d.Input("#B", "type=number"))),
-				d.Div(".large-4 columns", d.Label("C", d.Input("#C", "type=text"))),
-			),
-		}
-	} else {
-		return BodyVec{
-			d.Div(".row",
-				d.Div(".large-4 columns", &inlineLabel{Id: "A", Type: Number}),
-				d.Div(".large-4 columns",
-					d.Div(".row",
-						d.Div(".large-3 columns", d.Label(".right inline", "for=B", "B")),
-						d.Div(".large-9 columns", d.Input("#B", "type=number")))),
-				d.Div(".large-4 columns",
-					d.Div(".row",
-						d.Div(".large-3 columns", d.Label(".right inline", "for=C", "C")),
-						d.Div(".large-9 columns", d.Input("#C", "type=text")))),
-			),
-		}
-	}
+	A int     `min:"0" help:"A non-negative integer."`
+	B int     `form:",required"`
+	C string  `placeholder:"Some text"`
+	D float64 `label:"D (ratio)"`
 }
 
 type foo struct{}
@@ -238,8 +190,7 @@ tristique lacus sed ex viverra, quis bibendum mauris vehicula.`),
 				),
 				d.HR(),
 				d.Div(".panel",
-					d.Form(
-						d.Div(".row", &T{A: 1, C: "value"}),
+					form.New(d, &T{A: 1, C: "value"},
 						d.Div(".row",
 							d.Div(".large-12 columns",
 								d.A(".large radius button submit_on_click", "SUBMIT"))),
@@ -278,6 +229,10 @@ type execPage struct {
 	ExecCommand
 }
 
+func (p *execPage) PageHead(path string, d *Doc) []HeadNode {
+	return []HeadNode{&Title{"Exec Command"}}
+}
+
 func (p *execPage) PageBody(path string, d *Doc) BodyVec {
 	s := &standardBody{
 		BodyVec: p.Body(path, d),
@@ -287,7 +242,6 @@ func (p *execPage) PageBody(path string, d *Doc) BodyVec {
 }
 
 func (p *execPage) Body(path string, d *Doc) BodyVec {
-	p.ExecCommand = ExecCommand{Prompt: "Enter Command"}
 	return BodyVec{
 		d.H2("Exec Command"),
 		d.Div(".panel",
@@ -363,6 +317,26 @@ func (c *myCanvas) Event(x *canvas.Context, p r2.X) {
 
 type myCanvasPage struct {
 	canvas.Page
+	canvases []myCanvas
+}
+
+func newCanvasPage() (p *myCanvasPage) {
+	p = &myCanvasPage{
+		canvases: []myCanvas{
+			{id: "canvas1", greeting: "Hello 1"},
+			{id: "canvas2", greeting: "Hello 2"},
+		},
+	}
+	for i := range p.canvases {
+		c := &p.canvases[i]
+		p.Page.SetDrawer(c.id, c)
+		p.Page.SetListener(c.id, c)
+	}
+	return
+}
+
+func (p *myCanvasPage) PageHead(path string, d *Doc) []HeadNode {
+	return []HeadNode{&Title{"Canvas"}}
 }
 
 func (p *myCanvasPage) PageBody(path string, d *Doc) BodyVec {
@@ -371,15 +345,9 @@ func (p *myCanvasPage) PageBody(path string, d *Doc) BodyVec {
 }
 
 func (p *myCanvasPage) Body(path string, d *Doc) BodyVec {
-	cs := []myCanvas{
-		{id: "canvas1", greeting: "Hello 1"},
-		{id: "canvas2", greeting: "Hello 2"},
-	}
 	bv := BodyVec{d.H2("Canvas")}
-	for i := range cs {
-		p.Page.SetDrawer(cs[i].id, &cs[i])
-		p.Page.SetListener(cs[i].id, &cs[i])
-		bv = append(bv, d.Div(&cs[i]))
+	for i := range p.canvases {
+		bv = append(bv, d.Div(&p.canvases[i]))
 	}
 	return bv
 }
@@ -399,6 +367,25 @@ func (c *myElog) Body(d *Doc) BodyVec {
 
 type myElogPage struct {
 	canvas.Page
+	elogs []myElog
+}
+
+func newElogPage() (p *myElogPage) {
+	p = &myElogPage{
+		elogs: []myElog{
+			{id: "elog_canvas1"},
+		},
+	}
+	for i := range p.elogs {
+		c := &p.elogs[i]
+		p.Page.SetDrawer(c.id, c)
+		p.Page.SetListener(c.id, c)
+	}
+	return
+}
+
+func (p *myElogPage) PageHead(path string, d *Doc) []HeadNode {
+	return []HeadNode{&Title{"Event Log"}}
 }
 
 func (p *myElogPage) PageBody(path string, d *Doc) BodyVec {
@@ -407,14 +394,9 @@ func (p *myElogPage) PageBody(path string, d *Doc) BodyVec {
 }
 
 func (p *myElogPage) Body(path string, d *Doc) BodyVec {
-	cs := []myElog{
-		{id: "elog_canvas1"},
-	}
 	bv := BodyVec{d.H2("Event Log")}
-	for i := range cs {
-		p.Page.SetDrawer(cs[i].id, &cs[i])
-		p.Page.SetListener(cs[i].id, &cs[i])
-		bv = append(bv, d.Div(&cs[i]))
+	for i := range p.elogs {
+		bv = append(bv, d.Div(&p.elogs[i]))
 	}
 	return bv
 }
@@ -3,9 +3,11 @@ package main
 import (
 	"github.com/platinasystems/elib/elog"
 	"github.com/platinasystems/weeb"
+	"github.com/platinasystems/weeb/form"
 
 	"fmt"
 	"log"
+	"net/url"
 	"os/exec"
 	"time"
 )
@@ -25,6 +27,21 @@ func (l *Listener) Exec(args *[]string, result *string) (err error) {
 	return
 }
 
+// Decode and validate values submitted from form generated for T.
+// Validation errors are returned in reply so client can re-render form.
+func (l *Listener) SubmitT(values url.Values, reply *form.Errors) (err error) {
+	var t T
+	err = form.Decode(&t, values)
+	if errs, ok := err.(form.Errors); ok {
+		*reply = errs
+		return nil
+	}
+	if err == nil {
+		log.Printf("SubmitT: %+v", t)
+	}
+	return
+}
+
 var ackReply = "ack"
 var count = 0
 var printEvery = 100
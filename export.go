@@ -0,0 +1,160 @@
+// +build !js
+
+package weeb
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Page (or content, by URL path) which could not be exported.
+type ExportError struct {
+	Pattern string
+	Err     error
+}
+
+func (e *ExportError) Error() string { return e.Pattern + ": " + e.Err.Error() }
+
+// File name extensions for precompressed siblings by content encoding.
+var encodingExt = map[string]string{
+	"gzip": ".gz",
+	"br":   ".br",
+	"zstd": ".zst",
+}
+
+// Response captured in memory for export.
+type exportWriter struct {
+	header http.Header
+	status int
+	bytes.Buffer
+}
+
+func (w *exportWriter) Header() http.Header { return w.header }
+func (w *exportWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+func (w *exportWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.Buffer.Write(b)
+}
+
+// Render path as served returning response.  Handler panics are returned as errors.
+func exportGet(h http.Handler, path string) (w *exportWriter, err error) {
+	r, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return
+	}
+	w = &exportWriter{header: make(http.Header)}
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+	h.ServeHTTP(w, r)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return
+}
+
+func writeExportFile(name string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, b, 0666)
+}
+
+// Write static copy of site into directory dir: an index.html file for each page (e.g. dir/exec/index.html
+// for page /exec) and a file for each registered content (decoded when gzip encoded).  If precompressed is
+// set, encoded content and alternates are also written with encoding file name extensions (e.g. .gz).
+// Pages which cannot be rendered statically (patterns with parameters, pages responding with errors or
+// redirects) and content with encodings lacking a file name extension are skipped and returned.
+// Err is non-nil only if files cannot be written.
+func (s *Site) Export(dir string, precompressed bool) (skipped []*ExportError, err error) {
+	var patterns []string
+	for p := range s.PageByPath {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	for _, p := range patterns {
+		path := cleanPath(p)
+		if strings.ContainsAny(path, "{*") {
+			skipped = append(skipped, &ExportError{Pattern: p, Err: fmt.Errorf("pattern has parameters")})
+			continue
+		}
+		w, e := exportGet(http.HandlerFunc(s.servePage), path)
+		if e == nil && w.status != http.StatusOK {
+			e = fmt.Errorf("status %d %s", w.status, http.StatusText(w.status))
+		}
+		if e != nil {
+			skipped = append(skipped, &ExportError{Pattern: p, Err: e})
+			continue
+		}
+		name := filepath.Join(dir, filepath.FromSlash(path), "index.html")
+		if err = writeExportFile(name, w.Bytes()); err != nil {
+			return
+		}
+	}
+
+	cr := s.content()
+	for _, path := range cr.Paths() {
+		c, _ := cr.Lookup(path)
+		name := filepath.Join(dir, filepath.FromSlash(cleanPath(path)))
+		w, e := exportGet(c, path)
+		if e == nil && w.status != http.StatusOK {
+			e = fmt.Errorf("status %d %s", w.status, http.StatusText(w.status))
+		}
+		if e != nil {
+			return skipped, fmt.Errorf("%s: %v", path, e)
+		}
+		// Content which cannot be decoded is only written with encoding extension.
+		if enc := w.Header().Get("Content-Encoding"); len(enc) == 0 {
+			err = writeExportFile(name, w.Bytes())
+		} else if ext, ok := encodingExt[enc]; ok {
+			err = writeExportFile(name+ext, w.Bytes())
+		} else {
+			skipped = append(skipped, &ExportError{Pattern: path, Err: fmt.Errorf("unknown content encoding %s", enc)})
+			continue
+		}
+		if err != nil {
+			return
+		}
+		if !precompressed {
+			continue
+		}
+		for _, x := range append([]*Content{c}, c.Alternates...) {
+			ext, ok := encodingExt[x.ContentEncoding]
+			if !ok {
+				continue
+			}
+			var b []byte
+			if b, err = x.bytes(); err != nil {
+				return
+			}
+			if err = writeExportFile(name+ext, b); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Content as stored (i.e. encoded).
+func (c *Content) bytes() (b []byte, err error) {
+	rs, f, _, err := c.open()
+	if f != nil {
+		defer f.Close()
+	}
+	if err != nil {
+		return
+	}
+	return ioutil.ReadAll(rs)
+}
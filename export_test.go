@@ -0,0 +1,85 @@
+// +build !js
+
+package weeb
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/platinasystems/weeb/html"
+)
+
+func TestExport(t *testing.T) {
+	var r ContentRegistry
+	r.MustAdd(&Content{URLPath: "/js/a.js", Data: []byte("var a;")})
+	r.MustAdd(&Content{URLPath: "/js/z.js", Data: gzipped(t, "var z;"), ContentEncoding: "gzip",
+		Alternates: []*Content{{Data: []byte("BR"), ContentEncoding: "br"}}})
+	r.MustAdd(&Content{URLPath: "/js/d.js", Data: []byte("DEFLATE"), ContentEncoding: "deflate"})
+	s := &Site{
+		PageByPath: map[string]Page{
+			"/":       &testPage{"root"},
+			"/exec":   &testPage{"exec"},
+			"/page/":  &testPage{"page"},
+			"/p/{x}":  &testPage{"p"},
+			"/gone":   &responsePage{err: &StatusError{Code: http.StatusGone}},
+			"/moved":  &responsePage{err: &Redirect{URL: "/"}},
+			"/files/": &testPage{"files"},
+		},
+		Head:    []html.HeadNode{&html.Title{X: "T"}},
+		Content: &r,
+	}
+	for _, precompressed := range []bool{false, true} {
+		dir := t.TempDir()
+		skipped, err := s.Export(dir, precompressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range skipped {
+			got = append(got, e.Error())
+		}
+		want := []string{"/gone: status 410 Gone", "/moved: status 302 Found", "/p/{x}: pattern has parameters",
+			"/js/d.js: unknown content encoding deflate"}
+		if len(got) != len(want) {
+			t.Fatalf("got skipped %q want %q", got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("got skipped %q want %q", got[i], want[i])
+			}
+		}
+		files := []struct {
+			name, want string
+		}{
+			{"index.html", `<!DOCTYPE html><html><head><title>T</title></head><body><p>root /</p></body></html>`},
+			{"exec/index.html", `<!DOCTYPE html><html><head><title>T</title></head><body><p>exec /exec</p></body></html>`},
+			{"page/index.html", `<!DOCTYPE html><html><head><title>T</title></head><body><p>page /page/</p></body></html>`},
+			{"files/index.html", `<!DOCTYPE html><html><head><title>T</title></head><body><p>files /files/</p></body></html>`},
+			{"js/a.js", "var a;"},
+			{"js/z.js", "var z;"},
+			{"js/z.js.gz", "\x00"},
+			{"js/z.js.br", "BR"},
+			{"gone/index.html", ""},
+			{"p/{x}/index.html", ""},
+			{"js/d.js", ""},
+		}
+		for _, f := range files {
+			b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(f.name)))
+			exists := f.want != "" && (precompressed || filepath.Ext(f.name) != ".gz" && filepath.Ext(f.name) != ".br")
+			switch {
+			case !exists && err == nil:
+				t.Errorf("precompressed %v: unexpected file %s", precompressed, f.name)
+			case exists && err != nil:
+				t.Errorf("precompressed %v: %v", precompressed, err)
+			case exists && f.name == "js/z.js.gz":
+				if string(b) != string(gzipped(t, "var z;")) {
+					t.Errorf("%s: not stored gzip data", f.name)
+				}
+			case exists && string(b) != f.want:
+				t.Errorf("%s: got %q want %q", f.name, b, f.want)
+			}
+		}
+	}
+}
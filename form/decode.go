@@ -0,0 +1,152 @@
+package form
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validation errors keyed by field (input) name.
+type Errors map[string]string
+
+func (e Errors) Error() string {
+	var ns []string
+	for n := range e {
+		ns = append(ns, n)
+	}
+	sort.Strings(ns)
+	s := ""
+	for i, n := range ns {
+		if i > 0 {
+			s += "; "
+		}
+		s += n + ": " + e[n]
+	}
+	return s
+}
+
+// Decode submitted form values into struct pointed to by v using the same field names as New.
+// RPC payloads may be sent as url.Values.  Values are validated according to field tags
+// (required, min, max, pattern and options); fields which fail are reported in the returned
+// Errors and left unchanged.  Read-only fields are never set from submitted values.
+func Decode(v interface{}, values url.Values) error {
+	r := reflect.ValueOf(v)
+	if r.Kind() != reflect.Ptr {
+		return fmt.Errorf("form: decode into non-pointer %T", v)
+	}
+	fs, err := Fields(v)
+	if err != nil {
+		return err
+	}
+	r = r.Elem()
+	for r.Kind() == reflect.Ptr {
+		r = r.Elem()
+	}
+	errs := Errors{}
+	for i := range fs {
+		f := &fs[i]
+		if f.ReadOnly {
+			continue
+		}
+		vs, present := values[f.Name]
+		s := ""
+		if len(vs) > 0 {
+			s = vs[0]
+		}
+		if msg := f.decode(r.FieldByIndex(f.index), s, present); len(msg) > 0 {
+			errs[f.Name] = msg
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate and set field value from submitted string; returns error message on failure.
+func (f *Field) decode(v reflect.Value, s string, present bool) string {
+	if f.kind == reflect.Bool {
+		// Unchecked checkboxes are not submitted; required checkboxes must be checked.
+		checked := present && (s == "true" || s == "on")
+		if f.Required && !checked {
+			return "required"
+		}
+		v.SetBool(checked)
+		return ""
+	}
+	if len(s) == 0 {
+		if f.Required {
+			return "required"
+		}
+		if present {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return ""
+	}
+	if len(f.Options) > 0 && !contains(f.Options, s) {
+		return "must be one of " + strings.Join(f.Options, ", ")
+	}
+	if len(f.Pattern) > 0 {
+		// As for HTML pattern attribute, pattern must match entire value.
+		re, err := regexp.Compile("^(?:" + f.Pattern + ")$")
+		if err != nil || !re.MatchString(s) {
+			return "invalid format"
+		}
+	}
+	switch f.kind {
+	case reflect.String:
+		v.SetString(s)
+		return ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return "invalid integer"
+		}
+		if msg := f.checkRange(float64(x)); len(msg) > 0 {
+			return msg
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return "invalid non-negative integer"
+		}
+		if msg := f.checkRange(float64(x)); len(msg) > 0 {
+			return msg
+		}
+		v.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return "invalid number"
+		}
+		if msg := f.checkRange(x); len(msg) > 0 {
+			return msg
+		}
+		v.SetFloat(x)
+	}
+	return ""
+}
+
+func (f *Field) checkRange(x float64) string {
+	if min, err := strconv.ParseFloat(f.Min, 64); err == nil && x < min {
+		return "must be at least " + f.Min
+	}
+	if max, err := strconv.ParseFloat(f.Max, 64); err == nil && x > max {
+		return "must be at most " + f.Max
+	}
+	return ""
+}
+
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package form
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/platinasystems/weeb/html"
+)
+
+type testConfig struct {
+	ID      int     `form:"id,readonly"`
+	Speed   int     `min:"1" max:"100" form:",required"`
+	FEC     string  `options:"none,rs,fc"`
+	Name    string  `pattern:"[a-z]+[0-9]*"`
+	Enabled bool    `form:"enabled"`
+	Ratio   float64 `max:"1"`
+	Count   uint8
+}
+
+func TestDecode(t *testing.T) {
+	initial := testConfig{ID: 7, Speed: 10, FEC: "rs", Name: "eth0", Enabled: true, Ratio: 0.5, Count: 3}
+	for _, c := range []struct {
+		name   string
+		values url.Values
+		want   testConfig
+		errs   Errors
+	}{
+		{
+			name:   "valid",
+			values: url.Values{"id": {"9"}, "Speed": {"100"}, "FEC": {"fc"}, "Name": {"xe1"}, "enabled": {"true"}, "Ratio": {"0.25"}, "Count": {"255"}},
+			want:   testConfig{ID: 7, Speed: 100, FEC: "fc", Name: "xe1", Enabled: true, Ratio: 0.25, Count: 255},
+		},
+		{
+			name:   "unchecked checkbox and absent fields",
+			values: url.Values{"Speed": {"1"}},
+			want:   testConfig{ID: 7, Speed: 1, FEC: "rs", Name: "eth0", Ratio: 0.5, Count: 3},
+		},
+		{
+			name:   "empty optional fields are zeroed",
+			values: url.Values{"Speed": {"1"}, "FEC": {""}, "Ratio": {""}, "enabled": {"on"}},
+			want:   testConfig{ID: 7, Speed: 1, Name: "eth0", Enabled: true, Count: 3},
+		},
+		// Fields which fail keep their values; others are still set (e.g. unchecked checkbox).
+		{
+			name:   "required",
+			values: url.Values{"Speed": {""}},
+			want:   testConfig{ID: 7, Speed: 10, FEC: "rs", Name: "eth0", Enabled: false, Ratio: 0.5, Count: 3},
+			errs:   Errors{"Speed": "required"},
+		},
+		{
+			name:   "range",
+			values: url.Values{"Speed": {"500"}, "Ratio": {"1.5"}},
+			want:   testConfig{ID: 7, Speed: 10, FEC: "rs", Name: "eth0", Enabled: false, Ratio: 0.5, Count: 3},
+			errs:   Errors{"Speed": "must be at most 100", "Ratio": "must be at most 1"},
+		},
+		{
+			name:   "invalid numbers",
+			values: url.Values{"Speed": {"x"}, "Count": {"256"}, "Ratio": {"y"}},
+			want:   testConfig{ID: 7, Speed: 10, FEC: "rs", Name: "eth0", Enabled: false, Ratio: 0.5, Count: 3},
+			errs:   Errors{"Speed": "invalid integer", "Count": "invalid non-negative integer", "Ratio": "invalid number"},
+		},
+		{
+			name:   "options and anchored pattern",
+			values: url.Values{"Speed": {"2"}, "FEC": {"xx"}, "Name": {"eth0 x"}},
+			want:   testConfig{ID: 7, Speed: 2, FEC: "rs", Name: "eth0", Enabled: false, Ratio: 0.5, Count: 3},
+			errs:   Errors{"FEC": "must be one of none, rs, fc", "Name": "invalid format"},
+		},
+	} {
+		v := initial
+		err := Decode(&v, c.values)
+		if c.errs == nil && err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+		}
+		if c.errs != nil && !reflect.DeepEqual(err, c.errs) {
+			t.Errorf("%s: got errors %v want %v", c.name, err, c.errs)
+		}
+		if v != c.want {
+			t.Errorf("%s: got %+v want %+v", c.name, v, c.want)
+		}
+	}
+}
+
+// Required checkboxes must be checked as the rendered required attribute claims.
+func TestDecodeRequiredCheckbox(t *testing.T) {
+	type terms struct {
+		Accept bool `form:"accept,required"`
+	}
+	for _, c := range []struct {
+		values url.Values
+		want   bool
+		errs   Errors
+	}{
+		{url.Values{"accept": {"true"}}, true, nil},
+		{url.Values{"accept": {"on"}}, true, nil},
+		{url.Values{}, false, Errors{"accept": "required"}},
+		{url.Values{"accept": {""}}, false, Errors{"accept": "required"}},
+	} {
+		var v terms
+		err := Decode(&v, c.values)
+		if c.errs == nil && err != nil || c.errs != nil && !reflect.DeepEqual(err, c.errs) {
+			t.Errorf("%v: got errors %v want %v", c.values, err, c.errs)
+		}
+		if v.Accept != c.want {
+			t.Errorf("%v: got %v want %v", c.values, v.Accept, c.want)
+		}
+	}
+}
+
+func TestDecodeNonPointer(t *testing.T) {
+	if err := Decode(testConfig{}, nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// Re-rendered form shows submitted values of failing fields with their errors.
+func TestNewSubmittedValues(t *testing.T) {
+	v := testConfig{Speed: 5, FEC: "rs"}
+	values := url.Values{"Speed": {"500"}, "FEC": {"fc"}}
+	err := Decode(&v, values)
+	d := &html.Doc{}
+	m := New(d, &v, err, values).Markup(d)
+	for _, s := range []string{
+		`class="error" type="number" name="Speed" value="500"`,
+		`<small class="error">must be at most 100</small>`,
+		`<option selected>fc</option>`,
+	} {
+		if !strings.Contains(m, s) {
+			t.Errorf("missing %s in %s", s, m)
+		}
+	}
+}
+
+// Successful decode returns a nil error which New accepts.
+func TestNewDecoded(t *testing.T) {
+	v := testConfig{Speed: 5, FEC: "rs"}
+	values := url.Values{"Speed": {"50"}, "FEC": {"fc"}}
+	err := Decode(&v, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &html.Doc{}
+	m := New(d, &v, err, values, "method=POST").Markup(d)
+	for _, s := range []string{
+		`<form method="POST">`,
+		`type="number" name="Speed" value="50"`,
+		`<option selected>fc</option>`,
+	} {
+		if !strings.Contains(m, s) {
+			t.Errorf("missing %s in %s", s, m)
+		}
+	}
+	if strings.Contains(m, "error") {
+		t.Errorf("unexpected error in %s", m)
+	}
+}
+
+// Errors wrapped by callers still attach to fields.
+func TestNewWrappedErrors(t *testing.T) {
+	v := testConfig{Speed: 5}
+	err := fmt.Errorf("port eth0: %w", Decode(&v, url.Values{"Speed": {"0"}}))
+	d := &html.Doc{}
+	m := New(d, &v, err).Markup(d)
+	if !strings.Contains(m, `<small class="error">must be at least 1</small>`) {
+		t.Errorf("missing error in %s", m)
+	}
+}
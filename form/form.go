@@ -0,0 +1,315 @@
+// Forms generated from Go structs.
+package form
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/platinasystems/weeb/html"
+)
+
+// A form control for a struct field.  Struct tags set the field's properties:
+//
+//	form:"NAME,OPTION,..."  input name (default field name) or "-" to skip field
+//	                        options: hidden, readonly, required, textarea, type=INPUT-TYPE
+//	label:"TEXT"            label text (default field name)
+//	help:"TEXT"             help text written after control
+//	min:"X" max:"X" step:"X" pattern:"REGEXP" placeholder:"TEXT"
+//	options:"A,B,C"         enumerated values rendered as Select
+//
+// For example:
+//
+//	type PortConfig struct {
+//		Speed int    `label:"Speed (Gbps)" min:"1" max:"100" form:",required"`
+//		FEC   string `options:"none,rs,fc"`
+//		Notes string `form:",textarea" help:"Shown on status page."`
+//	}
+type Field struct {
+	Name, Label, Help string
+	Type              html.InputType
+	Min, Max, Step    string
+	Pattern           string
+	Placeholder       string
+	Options           []string
+	Hidden            bool
+	ReadOnly          bool
+	Required          bool
+	Textarea          bool
+
+	// Current value of field formatted as form value.
+	Value string
+
+	// Validation error message from Decode shown with control.
+	Error string
+
+	// Element id of control; default Name.  New prefixes names with the form's id or name
+	// so that forms for the same struct may share a document.
+	ID string
+
+	// Index of field for reflect.Value.FieldByIndex.
+	index []int
+	kind  reflect.Kind
+}
+
+// Input types by name (e.g. "email").
+var inputTypeMap = func() map[string]html.InputType {
+	m := make(map[string]html.InputType)
+	for t := html.InputType(1); t.String() != ""; t++ {
+		m[t.String()] = t
+	}
+	return m
+}()
+
+func structValue(v interface{}) (r reflect.Value, err error) {
+	r = reflect.ValueOf(v)
+	for r.Kind() == reflect.Ptr {
+		r = r.Elem()
+	}
+	if r.Kind() != reflect.Struct {
+		err = fmt.Errorf("form: %T is not a struct or pointer to struct", v)
+	}
+	return
+}
+
+// Fields of struct (or pointer to struct) v with supported kinds: bool, integers, floats and strings.
+// Fields of embedded structs are included; other fields are ignored.
+func Fields(v interface{}) (fs []Field, err error) {
+	var r reflect.Value
+	if r, err = structValue(v); err != nil {
+		return
+	}
+	fs = fields(r, nil, fs)
+	return
+}
+
+func fields(r reflect.Value, index []int, fs []Field) []Field {
+	t := r.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fi := append(append([]int{}, index...), i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fs = fields(r.Field(i), fi, fs)
+			continue
+		}
+		if len(sf.PkgPath) != 0 { // unexported
+			continue
+		}
+		f, ok := newField(sf, r.Field(i))
+		if !ok {
+			continue
+		}
+		f.index = fi
+		fs = append(fs, f)
+	}
+	return fs
+}
+
+func newField(sf reflect.StructField, v reflect.Value) (f Field, ok bool) {
+	f.kind = sf.Type.Kind()
+	switch f.kind {
+	case reflect.Bool:
+		f.Type = html.Checkbox
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f.Type = html.Number
+	case reflect.Float32, reflect.Float64:
+		f.Type = html.Number
+		f.Step = "any"
+	case reflect.String:
+		f.Type = html.Text
+	default:
+		return
+	}
+
+	tag := sf.Tag
+	f.Name = sf.Name
+	opts := strings.Split(tag.Get("form"), ",")
+	if opts[0] == "-" {
+		return
+	}
+	if len(opts[0]) > 0 {
+		f.Name = opts[0]
+	}
+	for _, o := range opts[1:] {
+		switch {
+		case o == "hidden":
+			f.Hidden = true
+		case o == "readonly":
+			f.ReadOnly = true
+		case o == "required":
+			f.Required = true
+		case o == "textarea":
+			f.Textarea = true
+		case strings.HasPrefix(o, "type="):
+			if t, found := inputTypeMap[o[len("type="):]]; found {
+				f.Type = t
+			}
+		}
+	}
+	if f.Hidden {
+		f.Type = html.Hidden
+	}
+
+	f.Label = sf.Name
+	if s, found := tag.Lookup("label"); found {
+		f.Label = s
+	}
+	f.Help = tag.Get("help")
+	f.Min = tag.Get("min")
+	f.Max = tag.Get("max")
+	if s, found := tag.Lookup("step"); found {
+		f.Step = s
+	}
+	f.Pattern = tag.Get("pattern")
+	f.Placeholder = tag.Get("placeholder")
+	if s := tag.Get("options"); len(s) > 0 {
+		f.Options = strings.Split(s, ",")
+	}
+
+	f.Value = formatValue(v)
+	ok = true
+	return
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	default:
+		return v.String()
+	}
+}
+
+func (f *Field) id() string {
+	if len(f.ID) > 0 {
+		return f.ID
+	}
+	return f.Name
+}
+
+// Control for field: Input, Select or Textarea.
+func (f *Field) control(d *html.Doc) html.InlineNode {
+	id := []html.Attr{{Name: "id", Value: f.id()}}
+	if len(f.Error) > 0 {
+		id = append(id, html.Attr{Name: "class", Value: "error"})
+	}
+	switch {
+	case len(f.Options) > 0 && !f.Hidden:
+		s := d.Select(id)
+		s.Name = f.Name
+		s.Required = f.Required
+		s.Disabled = f.ReadOnly
+		for _, o := range f.Options {
+			s.Options = append(s.Options, &html.Option{Value: o, Selected: o == f.Value})
+		}
+		return s
+
+	case f.Textarea && !f.Hidden:
+		t := d.Textarea(id)
+		t.Name = f.Name
+		t.Content = f.Value
+		t.Placeholder = f.Placeholder
+		t.Required = f.Required
+		t.ReadOnly = f.ReadOnly
+		return t
+
+	default:
+		i := d.Input(id)
+		i.InputType = f.Type
+		i.Name = f.Name
+		i.Min, i.Max, i.Step = f.Min, f.Max, f.Step
+		i.Pattern = f.Pattern
+		i.Placeholder = f.Placeholder
+		i.Required = f.Required
+		i.ReadOnly = f.ReadOnly
+		if f.Type == html.Checkbox {
+			i.Value = "true"
+			i.Checked = f.Value == "true"
+		} else {
+			i.Value = f.Value
+		}
+		return i
+	}
+}
+
+// Labelled control for field: <div class="field"><label>..</label>control<small class="help">..</small></div>
+func (f *Field) Body(d *html.Doc) html.BodyVec {
+	c := f.control(d)
+	if f.Hidden {
+		return html.BodyVec{c}
+	}
+	// Text is given as String nodes so that it is never taken as an attribute spec.
+	x := html.BodyVec{d.Label(html.Attr{Name: "for", Value: f.id()}, &html.String{X: f.Label}), c}
+	if len(f.Help) > 0 {
+		x = append(x, d.Small(".help", &html.String{X: f.Help}))
+	}
+	if len(f.Error) > 0 {
+		x = append(x, d.Small(".error", &html.String{X: f.Error}))
+	}
+	return html.BodyVec{d.Div(".field", x)}
+}
+
+// Form with a labelled control for each field of struct (or pointer to struct) v.
+// Errors (e.g. the possibly nil error returned by Decode) attach messages to their fields' controls;
+// errors which are not Errors panic.
+// Submitted url.Values given with errors are shown in controls of failing fields, since Decode
+// leaves those fields unchanged, so that users can correct their input.
+// Other arguments are as for Doc.Form (e.g. "method=POST", "action=/config", submit buttons).
+// Control ids are prefixed with the form's id or name (e.g. "#port" gives id port-Speed for field Speed);
+// forms without either have control ids equal to field names.
+func New(d *html.Doc, v interface{}, args ...interface{}) *html.Form {
+	fs, err := Fields(v)
+	if err != nil {
+		panic(err)
+	}
+	var (
+		errs   Errors
+		values url.Values
+		rest   []interface{}
+	)
+	for _, a := range args {
+		switch x := a.(type) {
+		case nil:
+			// Decode succeeded.
+		case error:
+			if !errors.As(x, &errs) {
+				panic(x)
+			}
+		case url.Values:
+			values = x
+		default:
+			rest = append(rest, a)
+		}
+	}
+	n := d.Form(rest...)
+	prefix := n.ID
+	if len(prefix) == 0 {
+		prefix, _ = n.Get("name")
+	}
+	var x html.BodyVec
+	for i := range fs {
+		f := &fs[i]
+		if len(prefix) > 0 {
+			f.ID = prefix + "-" + f.Name
+		}
+		f.Error = errs[f.Name]
+		if vs, ok := values[f.Name]; ok && len(f.Error) > 0 && len(vs) > 0 {
+			f.Value = vs[0]
+		}
+		x = append(x, f.Body(d)...)
+	}
+	n.X = append(x, n.X...)
+	return n
+}
@@ -0,0 +1,108 @@
+package form
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/platinasystems/weeb/html"
+)
+
+type portBase struct {
+	Name string `form:"name,readonly" label:"Port"`
+}
+
+type portConfig struct {
+	portBase
+	Speed   int     `label:"Speed (Gbps)" min:"1" max:"100" form:",required"`
+	FEC     string  `options:"none,rs,fc"`
+	Notes   string  `form:",textarea" placeholder:"none" help:"Shown on status page."`
+	Enabled bool    `form:"enabled"`
+	Ratio   float32 `step:"0.1"`
+	Email   string  `form:",type=email" pattern:".+@.+"`
+	Secret  string  `form:"-"`
+	Index   uint16  `form:",hidden"`
+	Ports   []int
+	private int
+}
+
+func TestFields(t *testing.T) {
+	v := portConfig{portBase: portBase{Name: "eth0"}, Speed: 10, FEC: "rs", Enabled: true, Ratio: 0.5, Index: 3}
+	fs, err := Fields(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range fs {
+		names = append(names, f.Name)
+	}
+	if got, want := strings.Join(names, " "), "name Speed FEC Notes enabled Ratio Email Index"; got != want {
+		t.Fatalf("got fields %s want %s", got, want)
+	}
+	for _, c := range []struct {
+		f    Field
+		want Field
+	}{
+		{fs[0], Field{Name: "name", Label: "Port", Type: html.Text, ReadOnly: true, Value: "eth0"}},
+		{fs[1], Field{Name: "Speed", Label: "Speed (Gbps)", Type: html.Number, Min: "1", Max: "100", Required: true, Value: "10"}},
+		{fs[4], Field{Name: "enabled", Label: "Enabled", Type: html.Checkbox, Value: "true"}},
+		{fs[5], Field{Name: "Ratio", Label: "Ratio", Type: html.Number, Step: "0.1", Value: "0.5"}},
+		{fs[6], Field{Name: "Email", Label: "Email", Type: html.Email, Pattern: ".+@.+"}},
+		{fs[7], Field{Name: "Index", Label: "Index", Type: html.Hidden, Hidden: true, Value: "3"}},
+	} {
+		f := c.f
+		f.index, f.kind = nil, 0
+		if !reflect.DeepEqual(f, c.want) {
+			t.Errorf("got %+v want %+v", f, c.want)
+		}
+	}
+	if _, err := Fields(3); err == nil {
+		t.Error("expected error for non-struct")
+	}
+}
+
+func TestNew(t *testing.T) {
+	v := portConfig{portBase: portBase{Name: "eth0"}, Speed: 10, FEC: "rs", Enabled: true}
+	d := &html.Doc{}
+	m := New(d, &v, "method=POST", "action=/port").Markup(d)
+	for _, s := range []string{
+		`<form method="POST" action="/port">`,
+		`<div class="field"><label for="name">Port</label><input id="name" type="text" name="name" value="eth0" readonly/></div>`,
+		`<label for="Speed">Speed (Gbps)</label><input id="Speed" type="number" name="Speed" value="10" min="1" max="100" required/>`,
+		`<select id="FEC" name="FEC"><option>none</option><option selected>rs</option><option>fc</option></select>`,
+		`<textarea id="Notes" name="Notes" placeholder="none"></textarea><small class="help">Shown on status page.</small>`,
+		`<input id="enabled" type="checkbox" name="enabled" value="true" checked/>`,
+		`<input id="Ratio" type="number" name="Ratio" value="0" step="0.1"/>`,
+		`<input id="Index" type="hidden" name="Index" value="0"/></form>`,
+	} {
+		if !strings.Contains(m, s) {
+			t.Errorf("missing %s in %s", s, m)
+		}
+	}
+	if strings.Contains(m, "Secret") || strings.Contains(m, "Ports") || strings.Contains(m, "private") {
+		t.Errorf("unexpected field in %s", m)
+	}
+}
+
+// Forms for the same struct in one document have distinct control ids.
+func TestNewIDs(t *testing.T) {
+	v := portConfig{portBase: portBase{Name: "eth0"}}
+	d := &html.Doc{}
+	a := New(d, &v, "#a").Markup(d)
+	b := New(d, &v, "name=b").Markup(d)
+	for _, c := range []struct{ m, s string }{
+		{a, `<form id="a">`},
+		{a, `<label for="a-Speed">Speed (Gbps)</label><input id="a-Speed" type="number" name="Speed"`},
+		{b, `<form name="b">`},
+		{b, `<label for="b-Speed">Speed (Gbps)</label><input id="b-Speed" type="number" name="Speed"`},
+	} {
+		if !strings.Contains(c.m, c.s) {
+			t.Errorf("missing %s in %s", c.s, c.m)
+		}
+	}
+	for _, id := range []string{"a-Speed", "b-Speed", "a-FEC", "b-FEC"} {
+		if d.BodyNodeById[id] == nil {
+			t.Errorf("no node with id %s", id)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// +build !js
+
+package form
+
+import (
+	"net/http"
+)
+
+// Decode form values posted in request r (or given in its URL query) into struct pointed to by v.
+func DecodeRequest(r *http.Request, v interface{}) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	return Decode(v, r.Form)
+}
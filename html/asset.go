@@ -0,0 +1,54 @@
+package html
+
+import (
+	"bufio"
+	"strings"
+)
+
+// Served form of an asset (script, style sheet, etc.).
+type Asset struct {
+	URL string
+	// Subresource integrity digest (e.g. sha384-BASE64).
+	Integrity string
+}
+
+// Resolves logical asset names (e.g. /js/foo.min.js) to served assets (e.g. /js/foo.1a2b3c4d.min.js).
+type AssetResolver interface {
+	ResolveAsset(name string) (a Asset, ok bool)
+}
+
+// Assets by logical name (e.g. manifest generated by weebgen for use by gopherjs clients).
+type AssetMap map[string]Asset
+
+func (m AssetMap) ResolveAsset(name string) (a Asset, ok bool) {
+	a, ok = m[name]
+	return
+}
+
+// Asset for URI: resolved through document assets if found; otherwise URI itself.
+func (d *Doc) asset(u URI) (a Asset) {
+	if d != nil && d.Assets != nil {
+		var ok bool
+		if a, ok = d.Assets.ResolveAsset(string(u)); ok {
+			return
+		}
+	}
+	a.URL = string(u)
+	return
+}
+
+// Write integrity and crossorigin attributes: explicit values if given; otherwise integrity from asset.
+// Cross origin requests need CORS for integrity checks so crossorigin defaults to anonymous.
+func writeIntegrity(w *bufio.Writer, a Asset, integrity, crossOrigin string) {
+	if len(integrity) == 0 {
+		integrity = a.Integrity
+	}
+	if len(integrity) == 0 {
+		writeAttrs(w, Attr{Name: "crossorigin", Value: crossOrigin})
+		return
+	}
+	if len(crossOrigin) == 0 && (strings.HasPrefix(a.URL, "//") || strings.Contains(a.URL, "://")) {
+		crossOrigin = "anonymous"
+	}
+	writeAttrs(w, Attr{Name: "integrity", Value: integrity}, Attr{Name: "crossorigin", Value: crossOrigin})
+}
@@ -0,0 +1,33 @@
+package html
+
+import "testing"
+
+func TestAssets(t *testing.T) {
+	d := &Doc{Assets: AssetMap{
+		"/js/a.js":   {URL: "/js/a.1234.js"},
+		"/css/b.css": {URL: "/css/b.5678.css"},
+		"/js/i.js":   {URL: "/js/i.js", Integrity: "sha256-abc"},
+	}}
+	for _, c := range []struct {
+		n    HeadNode
+		want string
+	}{
+		{&Script{Src: "/js/a.js"}, `<script src="/js/a.1234.js"></script>`},
+		{&Script{Src: "/js/other.js"}, `<script src="/js/other.js"></script>`},
+		{&Link{Rel: "stylesheet", Type: "text/css", Href: "/css/b.css"}, `<link rel="stylesheet" type="text/css" href="/css/b.5678.css"/>`},
+		{&Script{Src: "/js/i.js"}, `<script src="/js/i.js" integrity="sha256-abc"></script>`},
+		{&Script{Src: "/js/i.js", Integrity: "sha384-def"}, `<script src="/js/i.js" integrity="sha384-def"></script>`},
+		{&Script{Src: "/js/i.js", CrossOrigin: "use-credentials"}, `<script src="/js/i.js" integrity="sha256-abc" crossorigin="use-credentials"></script>`},
+		{&Script{Src: "https://cdn.example.com/x.js", Integrity: "sha256-x"}, `<script src="https://cdn.example.com/x.js" integrity="sha256-x" crossorigin="anonymous"></script>`},
+		{&Script{Src: "/js/a.js", CrossOrigin: "anonymous"}, `<script src="/js/a.1234.js" crossorigin="anonymous"></script>`},
+		{&Link{Rel: "stylesheet", Href: "//cdn.example.com/c.css", Integrity: "sha256-c"}, `<link rel="stylesheet" href="//cdn.example.com/c.css" integrity="sha256-c" crossorigin="anonymous"/>`},
+	} {
+		if got := c.n.Markup(d); got != c.want {
+			t.Errorf("got %s want %s", got, c.want)
+		}
+	}
+	// Documents without assets use URLs as given.
+	if got, want := (&Script{Src: "/js/a.js"}).Markup(&Doc{}), `<script src="/js/a.js"></script>`; got != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+}
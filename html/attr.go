@@ -2,8 +2,9 @@
 package html
 
 import (
-	"fmt"
+	"bufio"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -67,10 +68,16 @@ func (d *Doc) assignID(attrs *Attrs) string {
 	return attrs.ID
 }
 
+// User attribute NAME=VALUE.  Empty value is rendered as boolean attribute NAME.
+type Attr struct {
+	Name, Value string
+}
+
 type Attrs struct {
 	ID string
 	ClassID
-	user map[string]string
+	// User attributes kept in order of insertion so that markup is reproducible.
+	user []Attr
 }
 
 type I18NAttrs struct {
@@ -78,17 +85,65 @@ type I18NAttrs struct {
 }
 
 func (a Attrs) User(n string, vals ...string) Attrs {
-	if a.user == nil {
-		a.user = make(map[string]string)
-	}
 	v := ""
 	if len(vals) > 0 {
 		v = vals[0]
 	}
-	a.user[n] = v
+	for i := range a.user {
+		if a.user[i].Name == n {
+			a.user[i].Value = v
+			return a
+		}
+	}
+	a.user = append(a.user, Attr{Name: n, Value: v})
 	return a
 }
 
+// Lookup user attribute by name.
+func (a *Attrs) Get(n string) (v string, ok bool) {
+	for i := range a.user {
+		if a.user[i].Name == n {
+			return a.user[i].Value, true
+		}
+	}
+	return
+}
+
+// Remove and return user attribute with given name.
+func (a *Attrs) take(n string) (v string, ok bool) {
+	for i := range a.user {
+		if a.user[i].Name == n {
+			v, ok = a.user[i].Value, true
+			a.user = append(a.user[:i], a.user[i+1:]...)
+			return
+		}
+	}
+	return
+}
+
+// Remove named attribute setting *v to its value if present.
+func (a *Attrs) takeString(n string, v *string) {
+	if x, ok := a.take(n); ok {
+		*v = x
+	}
+}
+
+// Remove named attribute setting *v to its integer value if present and valid.
+func (a *Attrs) takeInt(n string, v *int) {
+	if x, ok := a.take(n); ok {
+		if i, err := strconv.Atoi(x); err == nil {
+			*v = i
+		}
+	}
+}
+
+// Remove named boolean attribute setting *v if present (e.g. "required", "required=" or Attr{Name: "required"}).
+func (a *Attrs) takeBool(n string, v *bool) {
+	if _, ok := a.take(n); ok {
+		*v = true
+	}
+}
+
 func (d *Doc) addAttrId(a *Attrs, n BodyNode) {
 	if d.BodyNodeById == nil {
 		d.BodyNodeById = make(map[string]BodyNode)
@@ -96,43 +151,95 @@ func (d *Doc) addAttrId(a *Attrs, n BodyNode) {
 	d.BodyNodeById[a.ID] = n
 }
 
+func (d *Doc) setID(n BodyNode, a *Attrs, id string) {
+	a.ID = id
+	if n != nil {
+		d.addAttrId(a, n)
+	}
+}
+
+// Add class(es) to any already present.
+func (d *Doc) addClass(a *Attrs, name string) {
+	if a.ClassID != 0 {
+		name = d.ClassByID.Strings[a.ClassID] + " " + name
+	}
+	a.ClassID = d.ClassByName(name)
+}
+
+func (d *Doc) setAttr(n BodyNode, a *Attrs, xs ...Attr) {
+	for _, x := range xs {
+		switch x.Name {
+		case "class":
+			if len(x.Value) > 0 {
+				a.ClassID = d.ClassByName(x.Value)
+			}
+		case "id":
+			d.setID(n, a, x.Value)
+		default:
+			*a = a.User(x.Name, x.Value)
+		}
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == ':'
+}
+
+func isNameByte(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9' || c == '-' || c == '.'
+}
+
+// Valid attribute name: so that text such as "1+1=2" is not taken as an attribute.
+func isAttrName(s string) bool {
+	if len(s) == 0 || !isNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isNameByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse attribute spec NAME=VALUE splitting at the first =, so VALUE may itself contain =.
+// VALUE may be quoted with " or ' (e.g. title="a b").  NAME without = has empty value.
+func parseAttrSpec(spec string) (a Attr, ok bool) {
+	a.Name = spec
+	if i := strings.IndexByte(spec, '='); i >= 0 {
+		a.Name, a.Value = spec[:i], spec[i+1:]
+		if v := a.Value; len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			a.Value = v[1 : len(v)-1]
+		}
+	}
+	ok = isAttrName(a.Name)
+	return
+}
+
 func (d *Doc) addAttr2(n BodyNode, a *Attrs, spec string, force bool) bool {
-	// .STRING is shorthand for class=STRING but only applies once
-	// For example d.Div(".foo", ".bar") => <div class=foo>.bar</div>)
-	if spec[0] == '.' && a.ClassID == 0 {
-		a.ClassID = d.ClassByName(spec[1:])
+	// .STRING is shorthand for class=STRING; repeated specs add further classes.
+	// For example d.Div(".foo", ".bar baz") => <div class="bar baz foo">
+	if len(spec) > 1 && spec[0] == '.' && isNameByte(spec[1]) && spec[1] != '.' {
+		d.addClass(a, spec[1:])
 		return true
 	}
 
-	if spec[0] == '#' && len(a.ID) == 0 {
-		a.ID = spec[1:]
-		if n != nil {
-			d.addAttrId(a, n)
-		}
+	// #STRING is shorthand for id=STRING but only applies once.
+	if len(spec) > 1 && spec[0] == '#' && isNameByte(spec[1]) && spec[1] != '.' && len(a.ID) == 0 {
+		d.setID(n, a, spec[1:])
 		return true
 	}
 
 	// Otherwise look for NAME=VALUE pair.
-	if !force && strings.Index(spec, "=") < 0 {
+	if !force && strings.IndexByte(spec, '=') < 0 {
 		return false
 	}
 
-	e := strings.Split(spec, "=")
-	if len(e) > 0 {
-		switch e[0] {
-		case "class":
-			if len(e[1]) > 0 {
-				a.ClassID = d.ClassByName(e[1])
-			}
-		default:
-			if len(e) == 1 || len(e[1]) == 0 {
-				*a = a.User(e[0])
-			} else {
-				*a = a.User(e[0], e[1])
-			}
-		}
+	x, ok := parseAttrSpec(spec)
+	if ok {
+		d.setAttr(n, a, x)
 	}
-	return true
+	return ok
 }
 
 func (d *Doc) addAttr(n BodyNode, a *Attrs, spec string) bool {
@@ -143,6 +250,35 @@ func (d *Doc) addAttrForce(n BodyNode, a *Attrs, spec string) bool {
 	return d.addAttr2(n, a, spec, true)
 }
 
+// All string arguments are taken as attributes (NAME without = is a boolean attribute).
+// Strings which are not attribute specs panic as for elements with no content.
+func (d *Doc) attrsForce(n BodyNode, attrs *Attrs, args ...interface{}) {
+	var ls []interface{}
+	for _, a := range args {
+		switch v := a.(type) {
+		case string:
+			if !d.addAttrForce(n, attrs, v) {
+				panic(v)
+			}
+
+		case Attr:
+			d.setAttr(n, attrs, v)
+
+		case []Attr:
+			d.setAttr(n, attrs, v...)
+
+		default:
+			if !isListenerArg(a) {
+				panic(v)
+			}
+			ls = append(ls, a)
+		}
+	}
+	if len(ls) > 0 {
+		d.addEventListener(attrs, ls...)
+	}
+}
+
 func (a *Attrs) Set(n BodyNode, d *Doc, spec string) {
 	d.addAttrForce(n, a, spec)
 }
@@ -158,6 +294,12 @@ func (d *Doc) addAttrs(n BodyNode, attrs *Attrs, args ...interface{}) (content s
 				sep = " "
 			}
 
+		case Attr:
+			d.setAttr(n, attrs, v)
+
+		case []Attr:
+			d.setAttr(n, attrs, v...)
+
 		default:
 			if isEventListener(v) {
 				ls = append(ls, v)
@@ -167,28 +309,35 @@ func (d *Doc) addAttrs(n BodyNode, attrs *Attrs, args ...interface{}) (content s
 		}
 	}
 	if len(ls) > 0 {
-		d.addEventListener(attrs, ls)
+		d.addEventListener(attrs, ls...)
 	}
 	return
 }
 
-func (a *Attrs) String(d *Doc) (s, sep string) {
-	s = ""
-	sep = ""
+// Write attributes each preceded by a space.
+func (a *Attrs) write(w *bufio.Writer, d *Doc) {
 	if len(a.ID) != 0 {
-		s += fmt.Sprintf("%sid=\"%s\"", sep, a.ID)
-		sep = " "
+		writeAttr(w, "id", a.ID)
 	}
 	if a.ClassID != 0 {
-		s += fmt.Sprintf("%sclass=\"%s\"", sep, d.ClassByID.Strings[a.ClassID])
-		sep = " "
+		writeAttr(w, "class", d.ClassByID.Strings[a.ClassID])
 	}
-	for k, v := range a.user {
-		if len(v) > 0 {
-			s += fmt.Sprintf("%s%s=\"%s\"", sep, k, v)
+	for _, u := range a.user {
+		if len(u.Value) > 0 {
+			writeAttr(w, u.Name, u.Value)
 		} else {
-			s += fmt.Sprintf("%s%s", sep, k)
+			writeBoolAttr(w, u.Name)
 		}
+	}
+}
+
+func (a *Attrs) String(d *Doc) (s, sep string) {
+	var b strings.Builder
+	w := bufio.NewWriter(&b)
+	a.write(w, d)
+	w.Flush()
+	s = strings.TrimPrefix(b.String(), " ")
+	if len(s) > 0 {
 		sep = " "
 	}
 	return
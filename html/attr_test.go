@@ -0,0 +1,95 @@
+package html
+
+import "testing"
+
+// User attributes are written in order of insertion on every render.
+func TestAttrOrder(t *testing.T) {
+	d := &Doc{}
+	for _, c := range []struct {
+		n    Node
+		want string
+	}{
+		{d.Div("z=1", "a=2", "m=3", ".c", "#i"), `<div id="i" class="c" z="1" a="2" m="3"></div>`},
+		{d.A("href=/x", "target=_blank", "rel=noopener", "x"), `<a target="_blank" rel="noopener" href="/x">x</a>`},
+		{d.Svg("width=10", "height=20", "viewBox=0 0 10 20"), `<svg width="10" height="20" viewBox="0 0 10 20"></svg>`},
+		{d.Circle("r=5", "cy=2", "cx=1"), `<circle r="5" cy="2" cx="1">`},
+		{d.Input("type=text", "size=4", "maxlength=8"), `<input size="4" maxlength="8" type="text"/>`},
+		{d.Form("method=POST", "b=1", "a=2", "action=/f"), `<form b="1" a="2" method="POST" action="/f"></form>`},
+	} {
+		for i := 0; i < 10; i++ {
+			if got := c.n.Markup(d); got != c.want {
+				t.Errorf("%T: got %s want %s", c.n, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+// Setting an attribute again replaces its value in place.
+func TestAttrsUser(t *testing.T) {
+	d := &Doc{}
+	var a Attrs
+	a = a.User("b", "1").User("a", "2").User("b", "3").User("hidden")
+	if s, _ := a.String(d); s != `b="3" a="2" hidden` {
+		t.Errorf("got %s", s)
+	}
+	if v, ok := a.Get("a"); !ok || v != "2" {
+		t.Errorf("Get a: got %q %v", v, ok)
+	}
+	if v, ok := a.take("b"); !ok || v != "3" {
+		t.Errorf("take b: got %q %v", v, ok)
+	}
+	if _, ok := a.Get("b"); ok {
+		t.Error("b not removed")
+	}
+	if s, sep := a.String(d); s != `a="2" hidden` || sep != " " {
+		t.Errorf("got %q %q", s, sep)
+	}
+}
+
+func TestParseAttrSpec(t *testing.T) {
+	for _, c := range []struct {
+		spec string
+		want Attr
+		ok   bool
+	}{
+		{"href=/search?q=a=b", Attr{"href", "/search?q=a=b"}, true},
+		{"style=width=10px", Attr{"style", "width=10px"}, true},
+		{`title="a b"`, Attr{"title", "a b"}, true},
+		{"title='say \"hi\"'", Attr{"title", `say "hi"`}, true},
+		{`title="unbalanced`, Attr{"title", `"unbalanced`}, true},
+		{`title=""`, Attr{"title", ""}, true},
+		{"required=", Attr{"required", ""}, true},
+		{"required", Attr{"required", ""}, true},
+		{"data-x.y:z=1", Attr{"data-x.y:z", "1"}, true},
+		{"1+1=2", Attr{"1+1", "2"}, false},
+		{"a b=c", Attr{"a b", "c"}, false},
+		{"=x", Attr{"", "x"}, false},
+	} {
+		if a, ok := parseAttrSpec(c.spec); a != c.want || ok != c.ok {
+			t.Errorf("%s: got %+v %v want %+v %v", c.spec, a, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestAttrSpecArgs(t *testing.T) {
+	d := &Doc{}
+	for _, c := range []struct {
+		n    Node
+		want string
+	}{
+		{d.A("href=/search?q=a=b", "find"), `<a href="/search?q=a=b">find</a>`},
+		{d.Div(`title="two words"`), `<div title="two words"></div>`},
+		// Repeated classes accumulate; id shorthand applies once.
+		{d.Div(".b", ".a c", "#x", "#y"), `<div id="x" class="a b c">#y</div>`},
+		// Text which is not an attribute spec is content.
+		{d.P("1+1=2"), `<p>1+1=2</p>`},
+		{d.P(".", "..x", "#"), `<p>. ..x #</p>`},
+		{d.Div(Attr{Name: "title", Value: "a=b c"}, []Attr{{"id", "i"}, {"class", "k"}}), `<div id="i" class="k" title="a=b c"></div>`},
+		{d.Circle("r=1", Attr{Name: "fill", Value: "red"}), `<circle r="1" fill="red">`},
+	} {
+		if got := c.n.Markup(d); got != c.want {
+			t.Errorf("%T: got %s want %s", c.n, got, c.want)
+		}
+	}
+}
@@ -2,11 +2,15 @@
 package html
 
 import (
+	"bufio"
 	"fmt"
 )
 
-// All HTML elements (Nodes) are capable of generating markup as a string.
+// All HTML elements (Nodes) are capable of generating markup.
 type Node interface {
+	// Write markup to buffered writer.
+	WriteMarkup(w *bufio.Writer, d *Doc)
+	// Markup as a string.
 	Markup(d *Doc) string
 }
 
@@ -33,22 +37,21 @@ type BlockNode interface {
 //go:generate gentemplate -d Package=html -id Body -d Type=BodyNode github.com/platinasystems/elib/vec.tmpl
 //go:generate gentemplate -d Package=html -id Block -d Type=BlockNode github.com/platinasystems/elib/vec.tmpl
 
-func (n *BodyVec) Markup(d *Doc) string {
-	s := ""
+func (n *BodyVec) WriteMarkup(w *bufio.Writer, d *Doc) {
 	for _, f := range *n {
-		s += f.Markup(d)
+		f.WriteMarkup(w, d)
 	}
-	return s
 }
 
-func (n *BlockVec) Markup(d *Doc) string {
-	s := ""
+func (n *BlockVec) WriteMarkup(w *bufio.Writer, d *Doc) {
 	for _, f := range *n {
-		s += f.Markup(d)
+		f.WriteMarkup(w, d)
 	}
-	return s
 }
 
+func (n *BodyVec) Markup(d *Doc) string  { return markup(n, d) }
+func (n *BlockVec) Markup(d *Doc) string { return markup(n, d) }
+
 type Flow struct {
 	Attrs
 	X BodyVec
@@ -57,6 +60,7 @@ type Flow struct {
 func (d *Doc) flow(n BodyNode, px *BodyVec, attrs *Attrs, args ...interface{}) {
 	sep := ""
 	x := *px
+	var ls []interface{}
 	for _, a := range args {
 		switch v := a.(type) {
 		case string:
@@ -65,6 +69,12 @@ func (d *Doc) flow(n BodyNode, px *BodyVec, attrs *Attrs, args ...interface{}) {
 				sep = " "
 			}
 
+		case Attr:
+			d.setAttr(n, attrs, v)
+
+		case []Attr:
+			d.setAttr(n, attrs, v...)
+
 		case int:
 			x = append(x, &String{fmt.Sprintf("%d", v)})
 			sep = " "
@@ -87,21 +97,28 @@ func (d *Doc) flow(n BodyNode, px *BodyVec, attrs *Attrs, args ...interface{}) {
 			x = append(x, bs...)
 
 		default:
-			panic(v)
+			if !isEventListener(a) {
+				panic(v)
+			}
+			ls = append(ls, a)
 		}
 	}
 
+	if len(ls) > 0 {
+		d.addEventListener(attrs, ls...)
+	}
+
 	*px = x
 }
 
-func (n *Flow) markup(d *Doc, tag string) string {
-	a, _ := n.Attrs.String(d)
-	return wrap(tag, a, n.X.Markup(d))
+func (n *Flow) write(w *bufio.Writer, d *Doc, tag string) {
+	n.Attrs.writeStartTag(w, d, tag)
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, tag)
 }
 
-func (n *Flow) Markup(d *Doc) string {
-	return n.X.Markup(d)
-}
+func (n *Flow) WriteMarkup(w *bufio.Writer, d *Doc) { n.X.WriteMarkup(w, d) }
+func (n *Flow) Markup(d *Doc) string                { return markup(n, d) }
 
 func (n *Flow) bodyvec() (r BodyVec) {
 	for i := range n.X {
@@ -121,7 +138,7 @@ func (d *Doc) block(n BodyNode, px *BlockVec, attrs *Attrs, args ...interface{})
 	var ls []interface{}
 	for _, a := range args {
 		var isEvent bool
-		if isEvent = isEventListener(a); isEvent {
+		if isEvent = isListenerArg(a); isEvent {
 			ls = append(ls, a)
 		}
 		switch v := a.(type) {
@@ -130,6 +147,13 @@ func (d *Doc) block(n BodyNode, px *BlockVec, attrs *Attrs, args ...interface{})
 				x = append(x, &String{sep + v})
 				sep = " "
 			}
+
+		case Attr:
+			d.setAttr(n, attrs, v)
+
+		case []Attr:
+			d.setAttr(n, attrs, v...)
+
 		case BlockNode:
 			x = append(x, v)
 
@@ -141,15 +165,16 @@ func (d *Doc) block(n BodyNode, px *BlockVec, attrs *Attrs, args ...interface{})
 	}
 
 	if len(ls) > 0 {
-		d.addEventListener(attrs, ls)
+		d.addEventListener(attrs, ls...)
 	}
 
 	*px = x
 }
 
-func (n *block) markup(d *Doc, tag string) string {
-	a, _ := n.Attrs.String(d)
-	return wrap(tag, a, n.X.Markup(d))
+func (n *block) write(w *bufio.Writer, d *Doc, tag string) {
+	n.Attrs.writeStartTag(w, d, tag)
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, tag)
 }
 
 func (n *block) bodyvec() (r BodyVec) {
@@ -159,14 +184,21 @@ func (n *block) bodyvec() (r BodyVec) {
 	return
 }
 
-func (n *Attrs) markup(d *Doc, tag string) string {
-	a, _ := n.String(d)
-	return fmt.Sprintf("<%s %s>", tag, a)
+// Write start tag <TAG ATTRS> leaving start tag open for additional attributes.
+func (n *Attrs) writeStart(w *bufio.Writer, d *Doc, tag string) {
+	w.WriteByte('<')
+	w.WriteString(tag)
+	n.write(w, d)
+}
+
+func (n *Attrs) writeStartTag(w *bufio.Writer, d *Doc, tag string) {
+	n.writeStart(w, d, tag)
+	w.WriteByte('>')
 }
 
-func (n *Attrs) markupEndTag(d *Doc, tag string) string {
-	a, _ := n.String(d)
-	return fmt.Sprintf("<%s %s></%s>", tag, a, tag)
+func (n *Attrs) writeEndTag(w *bufio.Writer, d *Doc, tag string) {
+	n.writeStartTag(w, d, tag)
+	writeEndTag(w, tag)
 }
 
 func (d *Doc) attrsOnly(n BodyNode, attrs *Attrs, args ...interface{}) {
@@ -185,10 +217,26 @@ func (n *String) node()       {}
 func (n *String) attrs() *Attrs    { return &Attrs{} }
 func (n *String) bodyVec() BodyVec { return BodyVec{} }
 
-func (n *String) Markup(d *Doc) string {
-	return n.X
+// Text is escaped (e.g. < becomes &lt;).
+func (n *String) WriteMarkup(w *bufio.Writer, d *Doc) { writeEscaped(w, n.X) }
+func (n *String) Markup(d *Doc) string                { return markup(n, d) }
+
+// Trusted HTML written verbatim without escaping.
+type Raw struct {
+	X string
 }
 
+func (n *Raw) bodyNode()   {}
+func (n *Raw) blockNode()  {}
+func (n *Raw) inlineNode() {}
+func (n *Raw) node()       {}
+
+func (n *Raw) attrs() *Attrs    { return &Attrs{} }
+func (n *Raw) bodyVec() BodyVec { return BodyVec{} }
+
+func (n *Raw) WriteMarkup(w *bufio.Writer, d *Doc) { w.WriteString(n.X) }
+func (n *Raw) Markup(d *Doc) string                { return markup(n, d) }
+
 // Paragraphs
 type P inline
 
@@ -196,7 +244,8 @@ func (n *P) blockNode() {}
 func (n *P) bodyNode()  {}
 func (n *P) node()      {}
 
-func (n *P) Markup(d *Doc) string { return (*inline)(n).markup(d, "p") }
+func (n *P) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "p") }
+func (n *P) Markup(d *Doc) string                { return markup(n, d) }
 
 func (n *P) attrs() *Attrs    { return &n.Attrs }
 func (n *P) bodyVec() BodyVec { return (*inline)(n).bodyvec() }
@@ -234,12 +283,19 @@ func (n *H6) bodyNode()  {}
 func (n *H6) blockNode() {}
 func (n *H6) node()      {}
 
-func (n *H1) Markup(d *Doc) string { return (*inline)(n).markup(d, "h1") }
-func (n *H2) Markup(d *Doc) string { return (*inline)(n).markup(d, "h2") }
-func (n *H3) Markup(d *Doc) string { return (*inline)(n).markup(d, "h3") }
-func (n *H4) Markup(d *Doc) string { return (*inline)(n).markup(d, "h4") }
-func (n *H5) Markup(d *Doc) string { return (*inline)(n).markup(d, "h5") }
-func (n *H6) Markup(d *Doc) string { return (*inline)(n).markup(d, "h6") }
+func (n *H1) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "h1") }
+func (n *H2) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "h2") }
+func (n *H3) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "h3") }
+func (n *H4) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "h4") }
+func (n *H5) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "h5") }
+func (n *H6) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "h6") }
+
+func (n *H1) Markup(d *Doc) string { return markup(n, d) }
+func (n *H2) Markup(d *Doc) string { return markup(n, d) }
+func (n *H3) Markup(d *Doc) string { return markup(n, d) }
+func (n *H4) Markup(d *Doc) string { return markup(n, d) }
+func (n *H5) Markup(d *Doc) string { return markup(n, d) }
+func (n *H6) Markup(d *Doc) string { return markup(n, d) }
 
 func (n *H1) attrs() *Attrs { return &n.Attrs }
 func (n *H2) attrs() *Attrs { return &n.Attrs }
@@ -301,9 +357,13 @@ func (n *LI) blockNode() {}
 func (n *LI) bodyNode()  {}
 func (n *LI) node()      {}
 
-func (n *UL) Markup(d *Doc) string { return (*Flow)(n).markup(d, "ul") }
-func (n *OL) Markup(d *Doc) string { return (*Flow)(n).markup(d, "ol") }
-func (n *LI) Markup(d *Doc) string { return (*Flow)(n).markup(d, "li") }
+func (n *UL) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "ul") }
+func (n *OL) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "ol") }
+func (n *LI) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "li") }
+
+func (n *UL) Markup(d *Doc) string { return markup(n, d) }
+func (n *OL) Markup(d *Doc) string { return markup(n, d) }
+func (n *LI) Markup(d *Doc) string { return markup(n, d) }
 
 func (n *UL) attrs() *Attrs { return &n.Attrs }
 func (n *OL) attrs() *Attrs { return &n.Attrs }
@@ -336,9 +396,10 @@ func (n *Pre) blockNode() {}
 func (n *Pre) bodyNode()  {}
 func (n *Pre) node()      {}
 
-func (n *Pre) Markup(d *Doc) string { return (*inline)(n).markup(d, "pre") }
-func (n *Pre) attrs() *Attrs        { return &n.Attrs }
-func (n *Pre) bodyVec() BodyVec     { return (*inline)(n).bodyvec() }
+func (n *Pre) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "pre") }
+func (n *Pre) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Pre) attrs() *Attrs                       { return &n.Attrs }
+func (n *Pre) bodyVec() BodyVec                    { return (*inline)(n).bodyvec() }
 
 func (d *Doc) Pre(args ...interface{}) (n *Pre) {
 	n = &Pre{}
@@ -357,15 +418,8 @@ type DLNode interface {
 	dlNode()
 }
 
-type DT struct {
-	Attrs
-	inline
-}
-
-type DD struct {
-	Flow
-	inline
-}
+type DT inline
+type DD Flow
 
 func (n *DD) bodyNode()  {}
 func (n *DD) dlNode()    {}
@@ -377,15 +431,89 @@ func (n *DT) bodyNode()  {}
 func (n *DT) dlNode()    {}
 func (n *DT) node()      {}
 
+func (n *DL) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStartTag(w, d, "dl")
+	for _, f := range n.X {
+		f.WriteMarkup(w, d)
+	}
+	writeEndTag(w, "dl")
+}
+
+func (n *DT) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "dt") }
+func (n *DD) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "dd") }
+
+func (n *DL) Markup(d *Doc) string { return markup(n, d) }
+func (n *DT) Markup(d *Doc) string { return markup(n, d) }
+func (n *DD) Markup(d *Doc) string { return markup(n, d) }
+
+func (n *DL) attrs() *Attrs { return &n.Attrs }
+func (n *DT) attrs() *Attrs { return &n.Attrs }
+func (n *DD) attrs() *Attrs { return &n.Attrs }
+
+func (n *DL) bodyVec() (r BodyVec) {
+	for i := range n.X {
+		r = append(r, n.X[i])
+	}
+	return
+}
+func (n *DT) bodyVec() BodyVec { return (*inline)(n).bodyvec() }
+func (n *DD) bodyVec() BodyVec { return (*Flow)(n).bodyvec() }
+
+func (d *Doc) DL(args ...interface{}) (n *DL) {
+	n = &DL{}
+	var ls []interface{}
+	for _, a := range args {
+		switch v := a.(type) {
+		case string:
+			if !d.addAttr(n, &n.Attrs, v) {
+				panic(v)
+			}
+
+		case Attr:
+			d.setAttr(n, &n.Attrs, v)
+
+		case []Attr:
+			d.setAttr(n, &n.Attrs, v...)
+
+		case DLNode:
+			n.X = append(n.X, v)
+
+		case []DLNode:
+			n.X = append(n.X, v...)
+
+		default:
+			if !isListenerArg(a) {
+				panic(v)
+			}
+			ls = append(ls, a)
+		}
+	}
+	if len(ls) > 0 {
+		d.addEventListener(&n.Attrs, ls...)
+	}
+	return
+}
+func (d *Doc) DT(args ...interface{}) (n *DT) {
+	n = &DT{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) DD(args ...interface{}) (n *DD) {
+	n = &DD{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
 type Div Flow
 
 func (n *Div) blockNode() {}
 func (n *Div) bodyNode()  {}
 func (n *Div) node()      {}
 
-func (n *Div) Markup(d *Doc) string { return (*Flow)(n).markup(d, "div") }
-func (n *Div) attrs() *Attrs        { return &n.Attrs }
-func (n *Div) bodyVec() BodyVec     { return (*Flow)(n).bodyvec() }
+func (n *Div) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "div") }
+func (n *Div) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Div) attrs() *Attrs                       { return &n.Attrs }
+func (n *Div) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
 func (d *Doc) Div(args ...interface{}) (n *Div) {
 	n = &Div{}
 	d.flow(n, &n.X, &n.Attrs, args...)
@@ -398,9 +526,10 @@ func (n *Nav) blockNode() {}
 func (n *Nav) bodyNode()  {}
 func (n *Nav) node()      {}
 
-func (n *Nav) Markup(d *Doc) string { return (*Flow)(n).markup(d, "nav") }
-func (n *Nav) attrs() *Attrs        { return &n.Attrs }
-func (n *Nav) bodyVec() BodyVec     { return (*Flow)(n).bodyvec() }
+func (n *Nav) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "nav") }
+func (n *Nav) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Nav) attrs() *Attrs                       { return &n.Attrs }
+func (n *Nav) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
 func (d *Doc) Nav(args ...interface{}) (n *Nav) {
 	n = &Nav{}
 	d.flow(n, &n.X, &n.Attrs, args...)
@@ -413,24 +542,198 @@ func (n *Section) blockNode() {}
 func (n *Section) bodyNode()  {}
 func (n *Section) node()      {}
 
-func (n *Section) Markup(d *Doc) string { return (*Flow)(n).markup(d, "section") }
-func (n *Section) attrs() *Attrs        { return &n.Attrs }
-func (n *Section) bodyVec() BodyVec     { return (*Flow)(n).bodyvec() }
+func (n *Section) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "section") }
+func (n *Section) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Section) attrs() *Attrs                       { return &n.Attrs }
+func (n *Section) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
 func (d *Doc) Section(args ...interface{}) (n *Section) {
 	n = &Section{}
 	d.flow(n, &n.X, &n.Attrs, args...)
 	return
 }
 
+// HTML5 sectioning and grouping elements.
+
+// Introductory content for page or section.
+type Header Flow
+
+func (n *Header) blockNode() {}
+func (n *Header) bodyNode()  {}
+func (n *Header) node()      {}
+
+func (n *Header) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "header") }
+func (n *Header) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Header) attrs() *Attrs                       { return &n.Attrs }
+func (n *Header) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
+func (d *Doc) Header(args ...interface{}) (n *Header) {
+	n = &Header{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Footer for page or section.
+type Footer Flow
+
+func (n *Footer) blockNode() {}
+func (n *Footer) bodyNode()  {}
+func (n *Footer) node()      {}
+
+func (n *Footer) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "footer") }
+func (n *Footer) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Footer) attrs() *Attrs                       { return &n.Attrs }
+func (n *Footer) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
+func (d *Doc) Footer(args ...interface{}) (n *Footer) {
+	n = &Footer{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Dominant content of document body.
+type Main Flow
+
+func (n *Main) blockNode() {}
+func (n *Main) bodyNode()  {}
+func (n *Main) node()      {}
+
+func (n *Main) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "main") }
+func (n *Main) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Main) attrs() *Attrs                       { return &n.Attrs }
+func (n *Main) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
+func (d *Doc) Main(args ...interface{}) (n *Main) {
+	n = &Main{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Self-contained composition (e.g. post or widget).
+type Article Flow
+
+func (n *Article) blockNode() {}
+func (n *Article) bodyNode()  {}
+func (n *Article) node()      {}
+
+func (n *Article) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "article") }
+func (n *Article) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Article) attrs() *Attrs                       { return &n.Attrs }
+func (n *Article) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
+func (d *Doc) Article(args ...interface{}) (n *Article) {
+	n = &Article{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Content tangentially related to content around it (e.g. sidebar).
+type Aside Flow
+
+func (n *Aside) blockNode() {}
+func (n *Aside) bodyNode()  {}
+func (n *Aside) node()      {}
+
+func (n *Aside) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "aside") }
+func (n *Aside) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Aside) attrs() *Attrs                       { return &n.Attrs }
+func (n *Aside) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
+func (d *Doc) Aside(args ...interface{}) (n *Aside) {
+	n = &Aside{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Self-contained figure with optional Figcaption.
+type Figure Flow
+
+func (n *Figure) blockNode() {}
+func (n *Figure) bodyNode()  {}
+func (n *Figure) node()      {}
+
+func (n *Figure) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "figure") }
+func (n *Figure) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Figure) attrs() *Attrs                       { return &n.Attrs }
+func (n *Figure) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
+func (d *Doc) Figure(args ...interface{}) (n *Figure) {
+	n = &Figure{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Caption for Figure.
+type Figcaption Flow
+
+func (n *Figcaption) blockNode() {}
+func (n *Figcaption) bodyNode()  {}
+func (n *Figcaption) node()      {}
+
+func (n *Figcaption) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "figcaption") }
+func (n *Figcaption) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Figcaption) attrs() *Attrs                       { return &n.Attrs }
+func (n *Figcaption) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
+func (d *Doc) Figcaption(args ...interface{}) (n *Figcaption) {
+	n = &Figcaption{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Disclosure widget: Summary followed by content shown when open.
+type Details Flow
+
+func (n *Details) blockNode() {}
+func (n *Details) bodyNode()  {}
+func (n *Details) node()      {}
+
+func (n *Details) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "details") }
+func (n *Details) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Details) attrs() *Attrs                       { return &n.Attrs }
+func (n *Details) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
+func (d *Doc) Details(args ...interface{}) (n *Details) {
+	n = &Details{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Summary (legend) for Details.
+type Summary inline
+
+func (n *Summary) blockNode() {}
+func (n *Summary) bodyNode()  {}
+func (n *Summary) node()      {}
+
+func (n *Summary) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "summary") }
+func (n *Summary) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Summary) attrs() *Attrs                       { return &n.Attrs }
+func (n *Summary) bodyVec() BodyVec                    { return (*inline)(n).bodyvec() }
+func (d *Doc) Summary(args ...interface{}) (n *Summary) {
+	n = &Summary{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Dialog box.
+type Dialog Flow
+
+func (n *Dialog) blockNode() {}
+func (n *Dialog) bodyNode()  {}
+func (n *Dialog) node()      {}
+
+func (n *Dialog) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "dialog") }
+func (n *Dialog) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Dialog) attrs() *Attrs                       { return &n.Attrs }
+func (n *Dialog) bodyVec() BodyVec                    { return (*Flow)(n).bodyvec() }
+func (d *Doc) Dialog(args ...interface{}) (n *Dialog) {
+	n = &Dialog{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
 type Blockquote block
 
 func (n *Blockquote) blockNode() {}
 func (n *Blockquote) bodyNode()  {}
 func (n *Blockquote) node()      {}
 
-func (n *Blockquote) Markup(d *Doc) string { return (*block)(n).markup(d, "blockquote") }
-func (n *Blockquote) attrs() *Attrs        { return &n.Attrs }
-func (n *Blockquote) bodyVec() BodyVec     { return (*block)(n).bodyvec() }
+func (n *Blockquote) WriteMarkup(w *bufio.Writer, d *Doc) { (*block)(n).write(w, d, "blockquote") }
+func (n *Blockquote) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Blockquote) attrs() *Attrs                       { return &n.Attrs }
+func (n *Blockquote) bodyVec() BodyVec                    { return (*block)(n).bodyvec() }
 func (d *Doc) Blockquote(args ...interface{}) (n *Blockquote) {
 	n = &Blockquote{}
 	d.block(n, &n.X, &n.Attrs, args...)
@@ -443,9 +746,10 @@ func (n *HR) blockNode() {}
 func (n *HR) bodyNode()  {}
 func (n *HR) node()      {}
 
-func (n *HR) Markup(d *Doc) string { return (*Attrs)(n).markup(d, "hr") }
-func (n *HR) attrs() *Attrs        { return (*Attrs)(n) }
-func (n *HR) bodyVec() BodyVec     { return BodyVec{} }
+func (n *HR) WriteMarkup(w *bufio.Writer, d *Doc) { (*Attrs)(n).writeStartTag(w, d, "hr") }
+func (n *HR) Markup(d *Doc) string                { return markup(n, d) }
+func (n *HR) attrs() *Attrs                       { return (*Attrs)(n) }
+func (n *HR) bodyVec() BodyVec                    { return BodyVec{} }
 func (d *Doc) HR(args ...interface{}) (n *HR) {
 	n = &HR{}
 	d.attrsOnly(n, (*Attrs)(n), args...)
@@ -459,9 +763,10 @@ func (n *Address) blockNode() {}
 func (n *Address) bodyNode()  {}
 func (n *Address) node()      {}
 
-func (n *Address) Markup(d *Doc) string { return (*inline)(n).markup(d, "address") }
-func (n *Address) attrs() *Attrs        { return &n.Attrs }
-func (n *Address) bodyVec() BodyVec     { return (*inline)(n).bodyvec() }
+func (n *Address) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "address") }
+func (n *Address) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Address) attrs() *Attrs                       { return &n.Attrs }
+func (n *Address) bodyVec() BodyVec                    { return (*inline)(n).bodyvec() }
 func (d *Doc) Address(args ...interface{}) (n *Address) {
 	n = &Address{}
 	d.inline(n, &n.X, &n.Attrs, args...)
@@ -475,9 +780,10 @@ func (n *Canvas) blockNode() {}
 func (n *Canvas) bodyNode()  {}
 func (n *Canvas) node()      {}
 
-func (n *Canvas) Markup(d *Doc) string { return (*Attrs)(n).markupEndTag(d, "canvas") }
-func (n *Canvas) attrs() *Attrs        { return (*Attrs)(n) }
-func (n *Canvas) bodyVec() BodyVec     { return BodyVec{} }
+func (n *Canvas) WriteMarkup(w *bufio.Writer, d *Doc) { (*Attrs)(n).writeEndTag(w, d, "canvas") }
+func (n *Canvas) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Canvas) attrs() *Attrs                       { return (*Attrs)(n) }
+func (n *Canvas) bodyVec() BodyVec                    { return BodyVec{} }
 func (d *Doc) Canvas(args ...interface{}) (n *Canvas) {
 	n = &Canvas{}
 	d.attrsOnly(n, (*Attrs)(n), args...)
@@ -1,7 +1,10 @@
 package html
 
 import (
-	"fmt"
+	"bufio"
+	"io"
+	"strings"
+
 	"github.com/platinasystems/elib"
 )
 
@@ -13,34 +16,124 @@ type Doc struct {
 	BodyNodeById       map[string]BodyNode
 	nAssignedIds       int
 	EventListenersById map[string][]interface{}
+	// Resolves script and link URLs given as logical asset names.
+	Assets AssetResolver
+}
+
+// Anything which can write its markup (nodes and node vectors).
+type markupWriter interface {
+	WriteMarkup(w *bufio.Writer, d *Doc)
+}
+
+// Markup as a string for given markup writer.
+func markup(n markupWriter, d *Doc) string {
+	var b strings.Builder
+	w := bufio.NewWriter(&b)
+	n.WriteMarkup(w, d)
+	w.Flush()
+	return b.String()
+}
+
+// Same escapes as html.EscapeString.
+var htmlEscaper = strings.NewReplacer(
+	`&`, "&amp;",
+	`'`, "&#39;",
+	`<`, "&lt;",
+	`>`, "&gt;",
+	`"`, "&#34;",
+)
+
+// Write text escaping HTML special characters.
+func writeEscaped(w *bufio.Writer, s string) { htmlEscaper.WriteString(w, s) }
+
+// Write attribute name="value" with value escaped.
+func writeAttr(w *bufio.Writer, name, value string) {
+	w.WriteByte(' ')
+	w.WriteString(name)
+	w.WriteString("=\"")
+	writeEscaped(w, value)
+	w.WriteByte('"')
+}
+
+// Write boolean attribute (e.g. defer).
+func writeBoolAttr(w *bufio.Writer, name string) {
+	w.WriteByte(' ')
+	w.WriteString(name)
+}
+
+// Write non-empty attributes.
+func writeAttrs(w *bufio.Writer, as ...Attr) {
+	for _, a := range as {
+		if len(a.Value) > 0 {
+			writeAttr(w, a.Name, a.Value)
+		}
+	}
 }
 
-func wrap(tag, attrs, content string) string {
-	sep := ""
-	if len(attrs) > 0 {
-		sep = " "
+type boolAttr struct {
+	name string
+	v    bool
+}
+
+// Write boolean attributes which are true.
+func writeBoolAttrs(w *bufio.Writer, as ...boolAttr) {
+	for _, a := range as {
+		if a.v {
+			writeBoolAttr(w, a.name)
+		}
 	}
-	return fmt.Sprintf("<%s%s%s>%s</%s>", tag, sep, attrs, content, tag)
+}
+
+func writeEndTag(w *bufio.Writer, tag string) {
+	w.WriteString("</")
+	w.WriteString(tag)
+	w.WriteByte('>')
 }
 
 func (d *Doc) Reset() { d.nAssignedIds = 0 }
 
-func (d *Doc) Markup() string {
-	s := fmt.Sprintf("<!DOCTYPE html>")
-	s += fmt.Sprintf("<html>")
+// Write document markup.  Write errors are sticky and returned by w.Flush.
+func (d *Doc) WriteMarkup(w *bufio.Writer) {
+	w.WriteString("<!DOCTYPE html>")
+	w.WriteString("<html>")
 
-	s += fmt.Sprintf("<head>")
+	w.WriteString("<head>")
 	for _, n := range d.Head {
-		s += n.Markup(d)
+		n.WriteMarkup(w, d)
 	}
-	s += fmt.Sprintf("</head>")
+	w.WriteString("</head>")
 
-	s += fmt.Sprintf("<body>")
-	for _, n := range d.Body {
-		s += n.Markup(d)
-	}
-	s += fmt.Sprintf("</body>")
+	w.WriteString("<body>")
+	d.Body.WriteMarkup(w, d)
+	w.WriteString("</body>")
+
+	w.WriteString("</html>")
+}
 
-	s += fmt.Sprintf("</html>")
-	return s
+// Counts bytes written for WriteTo.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(b []byte) (n int, err error) {
+	n, err = c.w.Write(b)
+	c.n += int64(n)
+	return
+}
+
+// Stream document markup to w (e.g. an http.ResponseWriter).
+func (d *Doc) WriteTo(w io.Writer) (n int64, err error) {
+	c := &countWriter{w: w}
+	b := bufio.NewWriter(c)
+	d.WriteMarkup(b)
+	err = b.Flush()
+	n = c.n
+	return
+}
+
+func (d *Doc) Markup() string {
+	var b strings.Builder
+	d.WriteTo(&b)
+	return b.String()
 }
@@ -0,0 +1,82 @@
+package html
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testDoc() *Doc {
+	d := &Doc{}
+	d.Head = []HeadNode{&Title{X: "T"}, &Script{Src: "/a.js"}}
+	d.Body = BodyVec{
+		d.Div(".x", d.P("hello", d.A("href=/b", "link"))),
+		d.UL(d.LI("one"), d.LI("two")),
+	}
+	return d
+}
+
+func TestDocWriteTo(t *testing.T) {
+	d := testDoc()
+	const want = `<!DOCTYPE html><html><head><title>T</title><script src="/a.js"></script></head>` +
+		`<body><div class="x"><p>hello<a href="/b">link</a></p></div><ul><li>one</li><li>two</li></ul></body></html>`
+	var b strings.Builder
+	n, err := d.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want {
+		t.Errorf("got %s want %s", b.String(), want)
+	}
+	if n != int64(len(want)) {
+		t.Errorf("wrote %d bytes want %d", n, len(want))
+	}
+	if m := d.Markup(); m != want {
+		t.Errorf("Markup: got %s want %s", m, want)
+	}
+}
+
+// Node Markup strings are the same as streamed markup.
+func TestNodeMarkup(t *testing.T) {
+	d := testDoc()
+	for _, n := range d.Body {
+		var b strings.Builder
+		w := bufio.NewWriter(&b)
+		n.WriteMarkup(w, d)
+		w.Flush()
+		if m := n.Markup(d); m != b.String() {
+			t.Errorf("%T: Markup %s != WriteMarkup %s", n, m, b.String())
+		}
+	}
+}
+
+type errWriter struct{ n int }
+
+func (w *errWriter) Write(b []byte) (int, error) {
+	if len(b) > w.n {
+		return w.n, errors.New("short write")
+	}
+	w.n -= len(b)
+	return len(b), nil
+}
+
+func TestDocWriteToError(t *testing.T) {
+	d := testDoc()
+	for i := 0; i < 8; i++ {
+		d.Body = append(d.Body, d.P(strings.Repeat("x", 1024)))
+	}
+	n, err := d.WriteTo(&errWriter{n: 100})
+	if err == nil || n != 100 {
+		t.Errorf("got %d %v want 100 short write", n, err)
+	}
+}
+
+func TestDefinitionList(t *testing.T) {
+	d := &Doc{}
+	n := d.DL(".terms", d.DT("MTU"), d.DD("Maximum ", d.Em("transmission"), "unit"), []DLNode{d.DT("a"), d.DT("b"), d.DD("c")})
+	const want = `<dl class="terms"><dt>MTU</dt><dd>Maximum <em>transmission</em> unit</dd><dt>a</dt><dt>b</dt><dd>c</dd></dl>`
+	if got := n.Markup(d); got != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+// User defined elements.
+package html
+
+import (
+	"bufio"
+)
+
+// Element is embedded in node types defined outside of this package (e.g. <details> or
+// web components) to make them body nodes.  For example:
+//
+//	type Details struct {
+//		html.BlockElement
+//		Open bool
+//	}
+//
+//	func NewDetails(d *html.Doc, args ...interface{}) (n *Details) {
+//		n = &Details{}
+//		d.InitElement(n, &n.Element, "details", args...)
+//		return
+//	}
+//
+// Elements are written as <Tag attrs>children</Tag>.  Types needing further attributes
+// define their own WriteMarkup using Attrs.WriteStart, WriteAttr and WriteEndTag.
+type Element struct {
+	Attrs
+	Tag string
+	X   BodyVec
+
+	// Node embedding this element, used by Markup to call its WriteMarkup.
+	self BodyNode
+}
+
+// Elements which may appear where block nodes are expected (e.g. inside Blockquote).
+type BlockElement struct{ Element }
+
+// Elements which may appear where inline nodes are expected (e.g. inside P or Span).
+type InlineElement struct{ Element }
+
+func (n *Element) bodyNode()         {}
+func (n *BlockElement) blockNode()   {}
+func (n *InlineElement) inlineNode() {}
+
+func (n *Element) attrs() *Attrs    { return &n.Attrs }
+func (n *Element) bodyVec() BodyVec { return n.X }
+
+func (n *Element) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStartTag(w, d, n.Tag)
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, n.Tag)
+}
+
+func (n *Element) Markup(d *Doc) string {
+	if n.self != nil {
+		return markup(n.self, d)
+	}
+	return markup(n, d)
+}
+
+// Initialize element e embedded in node n.  Arguments are as for Doc.Div: attribute
+// specs, child nodes, text and event listeners.  Nodes which are themselves event
+// listeners (e.g. implement ClickInterface) are registered as such.
+func (d *Doc) InitElement(n BodyNode, e *Element, tag string, args ...interface{}) {
+	e.Tag = tag
+	e.self = n
+	d.flow(n, &e.X, &e.Attrs, args...)
+	if isEventListener(n) {
+		d.addEventListener(&e.Attrs, n)
+		d.addAttrId(&e.Attrs, n)
+	}
+}
+
+// Write start tag <TAG ATTRS leaving it open for further attributes; caller writes closing >.
+func (a *Attrs) WriteStart(w *bufio.Writer, d *Doc, tag string) { a.writeStart(w, d, tag) }
+
+// Write attribute NAME="VALUE" escaping value.
+func WriteAttr(w *bufio.Writer, name, value string) { writeAttr(w, name, value) }
+
+// Write boolean attribute NAME.
+func WriteBoolAttr(w *bufio.Writer, name string) { writeBoolAttr(w, name) }
+
+// Write text escaping HTML special characters.
+func WriteText(w *bufio.Writer, s string) { writeEscaped(w, s) }
+
+func WriteEndTag(w *bufio.Writer, tag string) { writeEndTag(w, tag) }
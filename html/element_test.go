@@ -0,0 +1,78 @@
+package html_test
+
+import (
+	"bufio"
+	"testing"
+
+	"github.com/platinasystems/weeb/html"
+)
+
+// Elements as defined by packages other than html.
+type details struct {
+	html.BlockElement
+	Open bool
+}
+
+func newDetails(d *html.Doc, args ...interface{}) (n *details) {
+	n = &details{}
+	d.InitElement(n, &n.Element, "details", args...)
+	return
+}
+
+func (n *details) WriteMarkup(w *bufio.Writer, d *html.Doc) {
+	n.Attrs.WriteStart(w, d, "details")
+	if n.Open {
+		html.WriteBoolAttr(w, "open")
+	}
+	w.WriteByte('>')
+	n.X.WriteMarkup(w, d)
+	html.WriteEndTag(w, "details")
+}
+
+// Web component using default markup.
+type portStatus struct {
+	html.InlineElement
+}
+
+// Element which is itself a click listener.
+type button struct {
+	html.InlineElement
+}
+
+func (n *button) Click(e *html.MouseEvent) {}
+
+type clicker struct{}
+
+func (clicker) Click(e *html.MouseEvent) {}
+
+func TestElement(t *testing.T) {
+	d := &html.Doc{}
+	x := newDetails(d, "#det", ".c", d.P("text"), clicker{})
+	x.Open = true
+	s := &portStatus{}
+	d.InitElement(s, &s.Element, "port-status", "port=eth0", "up")
+	b := &button{}
+	d.InitElement(b, &b.Element, "button", "go")
+	// Block elements are block nodes and inline elements are inline nodes.
+	div := d.Div(x, d.P(s, b), d.Blockquote(newDetails(d)))
+
+	const det = `<details id="det" class="c" open><p>text</p></details>`
+	want := `<div>` + det + `<p><port-status port="eth0">up</port-status><button id="0">go</button></p>` +
+		`<blockquote><details></details></blockquote></div>`
+	if got := div.Markup(d); got != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+	// Markup uses WriteMarkup of embedding type.
+	if got := x.Markup(d); got != det {
+		t.Errorf("got %s want %s", got, det)
+	}
+	if d.BodyNodeById["det"] != x {
+		t.Error("element not found by id")
+	}
+	if ls := d.EventListenersById["det"]; len(ls) != 1 || ls[0] != (clicker{}) {
+		t.Errorf("details listeners %v", ls)
+	}
+	if ls := d.EventListenersById[b.ID]; len(ls) != 1 || ls[0] != b || d.BodyNodeById[b.ID] != b {
+		t.Errorf("button listeners %v", ls)
+	}
+}
@@ -0,0 +1,29 @@
+package html
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	d := &Doc{}
+	for _, c := range []struct {
+		n    Node
+		want string
+	}{
+		{d.P("a < b & c"), `<p>a &lt; b &amp; c</p>`},
+		{d.P(&String{X: `<script>alert("x")</script>`}), `<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</p>`},
+		{d.Span(&String{X: "it's"}), `<span>it&#39;s</span>`},
+		{d.Div(`title="><script>`), `<div title="&#34;&gt;&lt;script&gt;"></div>`},
+		{d.Div(`#a"b`), `<div id="a&#34;b"></div>`},
+		{d.Div(`.x"y`), `<div class="x&#34;y"></div>`},
+		{d.A("href=/a?x&y", "link"), `<a href="/a?x&amp;y">link</a>`},
+		{&Title{X: "A & B"}, `<title>A &amp; B</title>`},
+		{&Option{Value: "<eth0>"}, `<option>&lt;eth0&gt;</option>`},
+		{&Textarea{Content: "</textarea>"}, `<textarea>&lt;/textarea&gt;</textarea>`},
+		// Trusted markup and script content are written verbatim.
+		{d.P(&Raw{X: "<b>bold</b>"}), `<p><b>bold</b></p>`},
+		{&Script{Content: "if (a < b) {}"}, `<script>if (a < b) {}</script>`},
+	} {
+		if got := c.n.Markup(d); got != c.want {
+			t.Errorf("%T: got %s want %s", c.n, got, c.want)
+		}
+	}
+}
@@ -52,3 +52,12 @@ func isEventListener(x interface{}) bool {
 	}
 	return false
 }
+
+// Event listener argument to be registered on the node being constructed.
+// Nodes which are themselves listeners (see InitElement) register their own events.
+func isListenerArg(x interface{}) bool {
+	if _, ok := x.(BodyNode); ok {
+		return false
+	}
+	return isEventListener(x)
+}
@@ -0,0 +1,33 @@
+package html
+
+import "testing"
+
+type clicker struct{}
+
+func (clicker) Click(e *MouseEvent) {}
+
+// Listener arguments must be stored as listeners, not as a nested slice.
+func TestEventListenerArgs(t *testing.T) {
+	d := &Doc{}
+	for _, n := range []interface {
+		attrs() *Attrs
+	}{
+		d.Div(clicker{}),
+		d.Section(clicker{}),
+		d.P("text", clicker{}),
+		d.Span("text", clicker{}),
+		d.Svg(clicker{}),
+		d.Img("src=/a.png", clicker{}),
+		d.Iframe(clicker{}),
+		d.DL(clicker{}),
+		d.Datalist(d.Option("a"), clicker{}),
+	} {
+		ls := d.EventListenersById[n.attrs().ID]
+		if len(ls) != 1 {
+			t.Fatalf("%T: %d listeners", n, len(ls))
+		}
+		if _, ok := ls[0].(ClickInterface); !ok {
+			t.Errorf("%T: listener %T is not a ClickInterface", n, ls[0])
+		}
+	}
+}
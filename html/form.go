@@ -1,7 +1,8 @@
 package html
 
 import (
-	"fmt"
+	"bufio"
+	"strconv"
 )
 
 type FormNode interface {
@@ -37,20 +38,21 @@ var formMethodMap = map[string]FormMethod{
 	"POST": FormPost,
 }
 
-func (n *Form) Markup(d *Doc) string {
-	a, sep := n.Attrs.String(d)
-
+func (n *Form) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStart(w, d, "form")
 	if n.FormMethod != FormMethodNone {
-		a += fmt.Sprintf("%smethod=\"%s\"", sep, formMethodStrings[n.FormMethod])
-		sep = " "
+		writeAttr(w, "method", formMethodStrings[n.FormMethod])
 	}
 	if len(n.Action) > 0 {
-		a += fmt.Sprintf("%saction=\"%s\"", sep, n.Action)
-		sep = " "
+		writeAttr(w, "action", string(n.Action))
 	}
-	return wrap("form", a, n.X.Markup(d))
+	w.WriteByte('>')
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, "form")
 }
 
+func (n *Form) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *Form) attrs() *Attrs    { return &n.Attrs }
 func (n *Form) bodyVec() BodyVec { return n.Flow.bodyvec() }
 
@@ -60,14 +62,12 @@ func (d *Doc) Form(args ...interface{}) (n *Form) {
 	var ok bool
 	var u string
 
-	if u, ok = n.Attrs.user["method"]; ok {
+	if u, ok = n.Attrs.take("method"); ok {
 		n.FormMethod = formMethodMap[u]
-		delete(n.Attrs.user, "method")
 	}
 
-	if u, ok = n.Attrs.user["action"]; ok {
+	if u, ok = n.Attrs.take("action"); ok {
 		n.Action = URI(u)
-		delete(n.Attrs.user, "action")
 	}
 
 	return
@@ -83,15 +83,18 @@ func (n *Label) bodyNode()   {}
 func (n *Label) inlineNode() {}
 func (n *Label) node()       {}
 
-func (n *Label) Markup(d *Doc) string {
-	a, sep := n.Attrs.String(d)
+func (n *Label) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStart(w, d, "label")
 	if len(n.For) > 0 {
-		a += fmt.Sprintf("%sfor=\"%s\"", sep, n.For)
-		sep = " "
+		writeAttr(w, "for", n.For)
 	}
-	return wrap("label", a, n.X.Markup(d))
+	w.WriteByte('>')
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, "label")
 }
 
+func (n *Label) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *Label) attrs() *Attrs    { return &n.Attrs }
 func (n *Label) bodyVec() BodyVec { return n.inline.bodyvec() }
 
@@ -100,16 +103,30 @@ func (d *Doc) Label(args ...interface{}) (n *Label) {
 	d.inline(n, &n.X, &n.Attrs, args...)
 	var ok bool
 	var v string
-	if v, ok = n.Attrs.user["for"]; ok {
+	if v, ok = n.Attrs.take("for"); ok {
 		n.For = v
-		delete(n.Attrs.user, "for")
 	}
 	return
 }
 
+// Input control.  Typed fields may be given as attribute specs (e.g. d.Input("name=port",
+// "type=number", "min=1", "required")) or set directly.  Text which is not an attribute spec panics.
 type Input struct {
 	Attrs
 	InputType
+	Name        string
+	Value       string
+	Placeholder string
+	// Numeric, date or time bounds and step (e.g. "any") as written in attributes.
+	Min, Max, Step string
+	// Regular expression text input value must match.
+	Pattern string
+	// Id of Datalist giving suggested values.
+	List     string
+	Required bool
+	Checked  bool
+	Disabled bool
+	ReadOnly bool
 }
 
 type InputType int
@@ -126,34 +143,74 @@ const (
 	Image
 	Button
 	Number
+	Email
+	URL
+	Tel
+	Search
+	Date
+	TimeInput // Named so as not to conflict with Time element.
+	DatetimeLocal
+	Month
+	Week
+	Range
+	Color
 )
 
 var inputTypeStrings = []string{
-	Text:     "text",
-	Password: "password",
-	Checkbox: "checkbox",
-	Radio:    "radio",
-	Submit:   "submit",
-	Reset:    "reset",
-	File:     "file",
-	Hidden:   "hidden",
-	Image:    "image",
-	Button:   "button",
-	Number:   "number",
+	Text:          "text",
+	Password:      "password",
+	Checkbox:      "checkbox",
+	Radio:         "radio",
+	Submit:        "submit",
+	Reset:         "reset",
+	File:          "file",
+	Hidden:        "hidden",
+	Image:         "image",
+	Button:        "button",
+	Number:        "number",
+	Email:         "email",
+	URL:           "url",
+	Tel:           "tel",
+	Search:        "search",
+	Date:          "date",
+	TimeInput:     "time",
+	DatetimeLocal: "datetime-local",
+	Month:         "month",
+	Week:          "week",
+	Range:         "range",
+	Color:         "color",
 }
 
 var inputTypeMap = map[string]InputType{
-	"text":     Text,
-	"password": Password,
-	"checkbox": Checkbox,
-	"radio":    Radio,
-	"submit":   Submit,
-	"reset":    Reset,
-	"file":     File,
-	"hidden":   Hidden,
-	"image":    Image,
-	"button":   Button,
-	"number":   Number,
+	"text":           Text,
+	"password":       Password,
+	"checkbox":       Checkbox,
+	"radio":          Radio,
+	"submit":         Submit,
+	"reset":          Reset,
+	"file":           File,
+	"hidden":         Hidden,
+	"image":          Image,
+	"button":         Button,
+	"number":         Number,
+	"email":          Email,
+	"url":            URL,
+	"tel":            Tel,
+	"search":         Search,
+	"date":           Date,
+	"time":           TimeInput,
+	"datetime-local": DatetimeLocal,
+	"month":          Month,
+	"week":           Week,
+	"range":          Range,
+	"color":          Color,
+}
+
+func (t InputType) String() string {
+	if int(t) < len(inputTypeStrings) {
+		return inputTypeStrings[t]
+	}
+	return ""
 }
 
 func (n *Input) formNode()   {}
@@ -161,31 +218,110 @@ func (n *Input) bodyNode()   {}
 func (n *Input) inlineNode() {}
 func (n *Input) node()       {}
 
-func (n *Input) Markup(d *Doc) string {
-	a, sep := n.Attrs.String(d)
-	a += fmt.Sprintf("%stype=\"%s\"", sep, inputTypeStrings[n.InputType])
-	return fmt.Sprintf("<input %s/>", a)
+func (n *Input) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStart(w, d, "input")
+	writeAttrs(w,
+		Attr{"type", n.InputType.String()},
+		Attr{"name", n.Name},
+		Attr{"value", n.Value},
+		Attr{"placeholder", n.Placeholder},
+		Attr{"min", n.Min},
+		Attr{"max", n.Max},
+		Attr{"step", n.Step},
+		Attr{"pattern", n.Pattern},
+		Attr{"list", n.List})
+	writeBoolAttrs(w,
+		boolAttr{"required", n.Required},
+		boolAttr{"checked", n.Checked},
+		boolAttr{"disabled", n.Disabled},
+		boolAttr{"readonly", n.ReadOnly})
+	w.WriteString("/>")
 }
 
+func (n *Input) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *Input) attrs() *Attrs    { return &n.Attrs }
 func (n *Input) bodyVec() BodyVec { return BodyVec{} }
 
 func (d *Doc) Input(args ...interface{}) (n *Input) {
 	n = &Input{}
-	d.addAttrs(n, &n.Attrs, args...)
+	d.attrsForce(n, &n.Attrs, args...)
 	var ok bool
 	var u string
-	if u, ok = n.Attrs.user["type"]; ok {
-		n.InputType = inputTypeMap[u]
-		delete(n.Attrs.user, "type")
+	if u, ok = n.Attrs.take("type"); ok {
+		// Types not known here are passed through as given.
+		if n.InputType, ok = inputTypeMap[u]; !ok {
+			n.Attrs = n.Attrs.User("type", u)
+		}
 	} else {
 		n.InputType = Text
 	}
+	a := &n.Attrs
+	a.takeString("name", &n.Name)
+	a.takeString("value", &n.Value)
+	a.takeString("placeholder", &n.Placeholder)
+	a.takeString("min", &n.Min)
+	a.takeString("max", &n.Max)
+	a.takeString("step", &n.Step)
+	a.takeString("pattern", &n.Pattern)
+	a.takeString("list", &n.List)
+	a.takeBool("required", &n.Required)
+	a.takeBool("checked", &n.Checked)
+	a.takeBool("disabled", &n.Disabled)
+	a.takeBool("readonly", &n.ReadOnly)
+	return
+}
+
+// Suggested values for Input fields referencing this datalist's id
+// (e.g. d.Input("list=speeds"), d.Datalist("#speeds", d.Option("10G"), d.Option("100G"))).
+// Other content (e.g. text) is fallback for browsers without datalist support.
+type Datalist struct {
+	Attrs
+	Options []*Option
+	X       BodyVec
+}
+
+func (n *Datalist) formNode()   {}
+func (n *Datalist) bodyNode()   {}
+func (n *Datalist) inlineNode() {}
+func (n *Datalist) node()       {}
+
+func (n *Datalist) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStartTag(w, d, "datalist")
+	for _, o := range n.Options {
+		o.WriteMarkup(w, d)
+	}
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, "datalist")
+}
+
+func (n *Datalist) Markup(d *Doc) string { return markup(n, d) }
+
+func (n *Datalist) attrs() *Attrs    { return &n.Attrs }
+func (n *Datalist) bodyVec() BodyVec { return n.X }
+
+// Arguments are as for Doc.Div with *Option arguments giving the suggested values.
+func (d *Doc) Datalist(args ...interface{}) (n *Datalist) {
+	n = &Datalist{}
+	var rest []interface{}
+	for _, a := range args {
+		if o, ok := a.(*Option); ok {
+			n.Options = append(n.Options, o)
+		} else {
+			rest = append(rest, a)
+		}
+	}
+	d.flow(n, &n.X, &n.Attrs, rest...)
 	return
 }
 
 type Select struct {
 	Attrs
+	Name     string
+	Multiple bool
+	Required bool
+	Disabled bool
+	// Option and Optgroup nodes.
 	Options []OptionNode
 }
 
@@ -194,15 +330,22 @@ func (n *Select) bodyNode()   {}
 func (n *Select) inlineNode() {}
 func (n *Select) node()       {}
 
-func (n *Select) Markup(d *Doc) string {
-	a, _ := n.Attrs.String(d)
-	options := ""
+func (n *Select) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStart(w, d, "select")
+	writeAttrs(w, Attr{"name", n.Name})
+	writeBoolAttrs(w,
+		boolAttr{"multiple", n.Multiple},
+		boolAttr{"required", n.Required},
+		boolAttr{"disabled", n.Disabled})
+	w.WriteByte('>')
 	for _, o := range n.Options {
-		options += o.Markup(d)
+		o.WriteMarkup(w, d)
 	}
-	return wrap("select", a, options)
+	writeEndTag(w, "select")
 }
 
+func (n *Select) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *Select) attrs() *Attrs    { return &n.Attrs }
 func (n *Select) bodyVec() BodyVec { return BodyVec{} }
 
@@ -215,6 +358,12 @@ func (d *Doc) Select(args ...interface{}) (n *Select) {
 				panic(v)
 			}
 
+		case Attr:
+			d.setAttr(n, &n.Attrs, v)
+
+		case []Attr:
+			d.setAttr(n, &n.Attrs, v...)
+
 		case OptionNode:
 			n.Options = append(n.Options, v)
 
@@ -222,6 +371,11 @@ func (d *Doc) Select(args ...interface{}) (n *Select) {
 			panic(v)
 		}
 	}
+	a := &n.Attrs
+	a.takeString("name", &n.Name)
+	a.takeBool("multiple", &n.Multiple)
+	a.takeBool("required", &n.Required)
+	a.takeBool("disabled", &n.Disabled)
 	return
 }
 
@@ -230,6 +384,7 @@ type OptionNode interface {
 	optionNode()
 }
 
+// Option text is given by Value; it is also the submitted value unless a value attribute is given.
 type Option struct {
 	Attrs
 	Selected bool
@@ -242,23 +397,84 @@ func (n *Option) formNode()   {}
 func (n *Option) bodyNode()   {}
 func (n *Option) node()       {}
 
-func (n *Option) Markup(d *Doc) string {
-	a, _ := n.Attrs.String(d)
-	return wrap("option", a, n.Value)
+func (n *Option) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStart(w, d, "option")
+	writeBoolAttrs(w,
+		boolAttr{"selected", n.Selected},
+		boolAttr{"disabled", n.Disabled})
+	w.WriteByte('>')
+	writeEscaped(w, n.Value)
+	writeEndTag(w, "option")
 }
 
+func (n *Option) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *Option) attrs() *Attrs    { return &n.Attrs }
 func (n *Option) bodyVec() BodyVec { return BodyVec{} }
 
 func (d *Doc) Option(args ...interface{}) OptionNode {
 	n := &Option{}
 	n.Value = d.addAttrs(n, &n.Attrs, args...)
+	n.Attrs.takeBool("selected", &n.Selected)
+	n.Attrs.takeBool("disabled", &n.Disabled)
+	return n
+}
+
+// Group of options within a Select.
+type Optgroup struct {
+	Attrs
+	Label    string
+	Disabled bool
+	Options  []*Option
+}
+
+func (n *Optgroup) optionNode() {}
+func (n *Optgroup) formNode()   {}
+func (n *Optgroup) bodyNode()   {}
+func (n *Optgroup) node()       {}
+
+func (n *Optgroup) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStart(w, d, "optgroup")
+	writeAttrs(w, Attr{"label", n.Label})
+	writeBoolAttrs(w, boolAttr{"disabled", n.Disabled})
+	w.WriteByte('>')
+	for _, o := range n.Options {
+		o.WriteMarkup(w, d)
+	}
+	writeEndTag(w, "optgroup")
+}
+
+func (n *Optgroup) Markup(d *Doc) string { return markup(n, d) }
+
+func (n *Optgroup) attrs() *Attrs    { return &n.Attrs }
+func (n *Optgroup) bodyVec() BodyVec { return BodyVec{} }
+
+// Text arguments give the group's label (e.g. d.Optgroup("Ports", d.Option("eth0"), ...)).
+func (d *Doc) Optgroup(args ...interface{}) OptionNode {
+	n := &Optgroup{}
+	var rest []interface{}
+	for _, a := range args {
+		if o, ok := a.(*Option); ok {
+			n.Options = append(n.Options, o)
+		} else {
+			rest = append(rest, a)
+		}
+	}
+	n.Label = d.addAttrs(n, &n.Attrs, rest...)
+	n.Attrs.takeString("label", &n.Label)
+	n.Attrs.takeBool("disabled", &n.Disabled)
 	return n
 }
 
 type Textarea struct {
 	Attrs
-	Content string
+	Name        string
+	Placeholder string
+	Rows, Cols  int
+	Required    bool
+	Disabled    bool
+	ReadOnly    bool
+	Content     string
 }
 
 func (n *Textarea) formNode()   {}
@@ -266,37 +482,114 @@ func (n *Textarea) bodyNode()   {}
 func (n *Textarea) inlineNode() {}
 func (n *Textarea) node()       {}
 
-func (n *Textarea) Markup(d *Doc) string {
-	a, _ := n.Attrs.String(d)
-	return wrap("textarea", a, n.Content)
+func (n *Textarea) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStart(w, d, "textarea")
+	writeAttrs(w,
+		Attr{"name", n.Name},
+		Attr{"placeholder", n.Placeholder})
+	if n.Rows != 0 {
+		writeAttr(w, "rows", strconv.Itoa(n.Rows))
+	}
+	if n.Cols != 0 {
+		writeAttr(w, "cols", strconv.Itoa(n.Cols))
+	}
+	writeBoolAttrs(w,
+		boolAttr{"required", n.Required},
+		boolAttr{"disabled", n.Disabled},
+		boolAttr{"readonly", n.ReadOnly})
+	w.WriteByte('>')
+	writeEscaped(w, n.Content)
+	writeEndTag(w, "textarea")
 }
 
+func (n *Textarea) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *Textarea) attrs() *Attrs    { return &n.Attrs }
 func (n *Textarea) bodyVec() BodyVec { return BodyVec{} }
 
+// Text arguments give the initial content (e.g. d.Textarea("name=notes", "rows=4", notes)).
 func (d *Doc) Textarea(args ...interface{}) (n *Textarea) {
 	n = &Textarea{}
-	for _, a := range args {
-		switch v := a.(type) {
-		case string:
-			if !d.addAttr(n, &n.Attrs, v) {
-				panic(v)
-			}
-
-		default:
-			panic(v)
-		}
-	}
+	n.Content = d.addAttrs(n, &n.Attrs, args...)
+	a := &n.Attrs
+	a.takeString("name", &n.Name)
+	a.takeString("placeholder", &n.Placeholder)
+	a.takeInt("rows", &n.Rows)
+	a.takeInt("cols", &n.Cols)
+	a.takeBool("required", &n.Required)
+	a.takeBool("disabled", &n.Disabled)
+	a.takeBool("readonly", &n.ReadOnly)
 	return
 }
 
+// Group of form controls with optional caption (Legend).
+// Disabled fieldsets disable all of their controls.
 type Fieldset struct {
-	Attrs
+	Flow
+	Legend   *Legend
+	Disabled bool
 }
 
+func (n *Fieldset) formNode()  {}
 func (n *Fieldset) blockNode() {}
 func (n *Fieldset) bodyNode()  {}
 func (n *Fieldset) node()      {}
 
-func (n *Fieldset) attrs() *Attrs    { return &n.Attrs }
-func (n *Fieldset) bodyVec() BodyVec { return BodyVec{} }
+func (n *Fieldset) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStart(w, d, "fieldset")
+	if n.Disabled {
+		writeBoolAttr(w, "disabled")
+	}
+	w.WriteByte('>')
+	if n.Legend != nil {
+		n.Legend.WriteMarkup(w, d)
+	}
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, "fieldset")
+}
+
+func (n *Fieldset) Markup(d *Doc) string { return markup(n, d) }
+func (n *Fieldset) attrs() *Attrs        { return &n.Attrs }
+
+func (n *Fieldset) bodyVec() (r BodyVec) {
+	if n.Legend != nil {
+		r = append(r, n.Legend)
+	}
+	return append(r, n.Flow.bodyvec()...)
+}
+
+// Arguments are as for Doc.Div; a *Legend argument becomes the fieldset's legend and
+// "disabled" (or "disabled=" or Attr{Name: "disabled"}) disables the fieldset.
+func (d *Doc) Fieldset(args ...interface{}) (n *Fieldset) {
+	n = &Fieldset{}
+	var rest []interface{}
+	for _, a := range args {
+		if l, ok := a.(*Legend); ok && n.Legend == nil {
+			n.Legend = l
+		} else if a == "disabled" {
+			// Boolean attribute rather than text content.
+			n.Disabled = true
+		} else {
+			rest = append(rest, a)
+		}
+	}
+	d.flow(n, &n.X, &n.Attrs, rest...)
+	n.Attrs.takeBool("disabled", &n.Disabled)
+	return
+}
+
+// Caption for Fieldset.
+type Legend inline
+
+func (n *Legend) bodyNode() {}
+func (n *Legend) node()     {}
+
+func (n *Legend) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "legend") }
+func (n *Legend) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Legend) attrs() *Attrs                       { return &n.Attrs }
+func (n *Legend) bodyVec() BodyVec                    { return (*inline)(n).bodyvec() }
+func (d *Doc) Legend(args ...interface{}) (n *Legend) {
+	n = &Legend{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
@@ -0,0 +1,102 @@
+package html
+
+import "testing"
+
+func TestFieldset(t *testing.T) {
+	d := &Doc{}
+	for _, c := range []struct {
+		n    Node
+		want string
+	}{
+		{d.Fieldset(d.Legend("Port"), d.Label("Speed"), d.Input("type=number")),
+			`<fieldset><legend>Port</legend><label>Speed</label><input type="number"/></fieldset>`},
+		// Legend is written first wherever it is given.
+		{d.Fieldset(".f", "disabled=", d.P("x"), d.Legend("L")), `<fieldset class="f" disabled><legend>L</legend><p>x</p></fieldset>`},
+		{d.Fieldset(Attr{Name: "disabled"}), `<fieldset disabled></fieldset>`},
+		{d.Fieldset("#f", "disabled", d.Input("name=a")), `<fieldset id="f" disabled><input type="text" name="a"/></fieldset>`},
+		{d.Fieldset(d.P("disabled")), `<fieldset><p>disabled</p></fieldset>`},
+	} {
+		if got := c.n.Markup(d); got != c.want {
+			t.Errorf("got %s want %s", got, c.want)
+		}
+	}
+}
+
+func TestFormControls(t *testing.T) {
+	d := &Doc{}
+	for _, c := range []struct {
+		n    Node
+		want string
+	}{
+		{d.Input("type=number", "name=port", "value=8", "min=1", "max=64", "step=1", "required"),
+			`<input type="number" name="port" value="8" min="1" max="64" step="1" required/>`},
+		{d.Input("name=n", "placeholder=a \"b\"", "pattern=[a-z]+", "disabled=", Attr{Name: "readonly"}),
+			`<input type="text" name="n" placeholder="a &#34;b&#34;" pattern="[a-z]+" disabled readonly/>`},
+		{d.Input("type=checkbox", "checked", "data-x=1"), `<input data-x="1" type="checkbox" checked/>`},
+		{&Input{InputType: Text, Name: "x", Value: `"<v>"`}, `<input type="text" name="x" value="&#34;&lt;v&gt;&#34;"/>`},
+		{d.Select("name=fec", "required=", d.Option("none"), d.Option("rs", "selected="), d.Option("fc", "disabled=")),
+			`<select name="fec" required><option>none</option><option selected>rs</option><option disabled>fc</option></select>`},
+		{d.Select("name=port", d.Optgroup("Front", "disabled=", d.Option("eth0"), d.Option("eth1")), d.Option("mgmt")),
+			`<select name="port"><optgroup label="Front" disabled><option>eth0</option><option>eth1</option></optgroup><option>mgmt</option></select>`},
+		{d.Textarea("name=notes", "rows=4", "cols=x", "readonly=", "a < b"), `<textarea name="notes" rows="4" readonly>a &lt; b</textarea>`},
+	} {
+		if got := c.n.Markup(d); got != c.want {
+			t.Errorf("got %s want %s", got, c.want)
+		}
+	}
+}
+
+// Inputs have no content so text which is not an attribute spec is an error.
+func TestInputText(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	d := &Doc{}
+	d.Input("name=x", "some text")
+}
+
+func TestInputTypes(t *testing.T) {
+	d := &Doc{}
+	for _, s := range []string{
+		"text", "password", "checkbox", "radio", "submit", "reset", "file", "hidden", "image", "button", "number",
+		"email", "url", "tel", "search", "date", "time", "datetime-local", "month", "week", "range", "color",
+	} {
+		n := d.Input("type=" + s)
+		if n.InputType.String() != s {
+			t.Errorf("%s: got type %d %q", s, n.InputType, n.InputType.String())
+		}
+		if got, want := n.Markup(d), `<input type="`+s+`"/>`; got != want {
+			t.Errorf("got %s want %s", got, want)
+		}
+	}
+}
+
+func TestUnknownInputType(t *testing.T) {
+	d := &Doc{}
+	n := d.Input("type=x-custom", "name=a")
+	if n.InputType != 0 {
+		t.Errorf("got type %v", n.InputType)
+	}
+	if got, want := n.Markup(d), `<input type="x-custom" name="a"/>`; got != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+}
+
+func TestDatalist(t *testing.T) {
+	d := &Doc{}
+	x := d.Div(d.Input("list=speeds", "name=speed"), d.Datalist("#speeds", d.Option("10G"), d.Option("100G")))
+	const want = `<div><input type="text" name="speed" list="speeds"/><datalist id="speeds"><option>10G</option><option>100G</option></datalist></div>`
+	if got := x.Markup(d); got != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+	// Text is fallback content and listeners are registered as for other elements.
+	l := d.Datalist("#l", "10G or 100G", d.Option("10G"), clicker{})
+	if got, want := l.Markup(d), `<datalist id="l"><option>10G</option>10G or 100G</datalist>`; got != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+	if ls := d.EventListenersById["l"]; len(ls) != 1 || ls[0] != (clicker{}) {
+		t.Errorf("got listeners %v", ls)
+	}
+}
@@ -1,7 +1,7 @@
 package html
 
 import (
-	"fmt"
+	"bufio"
 )
 
 type HeadNode interface {
@@ -16,10 +16,14 @@ type Title struct {
 func (n *Title) headNode() {}
 func (n *Title) node()     {}
 
-func (n *Title) Markup(d *Doc) string {
-	return wrap("title", "", n.X)
+func (n *Title) WriteMarkup(w *bufio.Writer, d *Doc) {
+	w.WriteString("<title>")
+	writeEscaped(w, n.X)
+	writeEndTag(w, "title")
 }
 
+func (n *Title) Markup(d *Doc) string { return markup(n, d) }
+
 // Document base.
 type Base struct {
 	URI
@@ -28,10 +32,14 @@ type Base struct {
 func (n *Base) headNode() {}
 func (n *Base) node()     {}
 
-func (n *Base) Markup(d *Doc) (v string) {
-	return fmt.Sprintf("<base href=\"%s\"/>", n.URI)
+func (n *Base) WriteMarkup(w *bufio.Writer, d *Doc) {
+	w.WriteString("<base")
+	writeAttr(w, "href", string(n.URI))
+	w.WriteString("/>")
 }
 
+func (n *Base) Markup(d *Doc) string { return markup(n, d) }
+
 // A character encoding, as per [RFC2045].
 type Charset string
 
@@ -57,34 +65,47 @@ type Meta struct {
 func (n *Meta) headNode() {}
 func (n *Meta) node()     {}
 
-func (n *Meta) Markup(d *Doc) (v string) {
-	a := ""
+func (n *Meta) WriteMarkup(w *bufio.Writer, d *Doc) {
+	w.WriteString("<meta")
 	switch {
 	case len(n.Charset) != 0:
-		a += fmt.Sprintf("charset=\"%s\"", n.Charset)
+		writeAttr(w, "charset", string(n.Charset))
 	case len(n.HttpEquiv) != 0:
-		a += fmt.Sprintf("http-equiv=\"%s\" content=\"%s\"", n.HttpEquiv, n.Content)
+		writeAttr(w, "http-equiv", n.HttpEquiv)
+		writeAttr(w, "content", n.Content)
 	default:
-		a += fmt.Sprintf("name=\"%s\" content=\"%s\"", n.Name, n.Content)
+		writeAttr(w, "name", n.Name)
+		writeAttr(w, "content", n.Content)
 	}
-	v = fmt.Sprintf("<meta %s/>", a)
-	return
+	w.WriteString("/>")
 }
 
+func (n *Meta) Markup(d *Doc) string { return markup(n, d) }
+
 type Link struct {
 	Charset
 	Href URI
 	Type ContentType
 	Rel  string
+	// Subresource integrity; default from document assets.
+	Integrity   string
+	CrossOrigin string
 }
 
 func (n *Link) headNode() {}
 func (n *Link) node()     {}
 
-func (n *Link) Markup(d *Doc) (v string) {
-	return fmt.Sprintf("<link rel=\"%s\" type=\"%s\" href=\"%s\"/>", n.Rel, n.Type, n.Href)
+func (n *Link) WriteMarkup(w *bufio.Writer, d *Doc) {
+	a := d.asset(n.Href)
+	w.WriteString("<link")
+	writeAttrs(w, Attr{Name: "rel", Value: n.Rel}, Attr{Name: "type", Value: string(n.Type)})
+	writeAttr(w, "href", a.URL)
+	writeIntegrity(w, a, n.Integrity, n.CrossOrigin)
+	w.WriteString("/>")
 }
 
+func (n *Link) Markup(d *Doc) string { return markup(n, d) }
+
 type Style struct {
 	// Content type of style language (e.g. text/css).
 	ContentType
@@ -100,32 +121,38 @@ type Script struct {
 	Src     URI
 	Async   bool
 	Defer   bool
-	Content string
+	Content string // Written verbatim: script content is not escaped.
+	// Subresource integrity for Src; default from document assets.
+	Integrity   string
+	CrossOrigin string
 }
 
 func (n *Script) headNode() {}
 func (n *Script) bodyNode() {}
 func (n *Script) node()     {}
 
-func (n *Script) Markup(d *Doc) (v string) {
-	a := ""
-	sep := ""
+func (n *Script) WriteMarkup(w *bufio.Writer, d *Doc) {
+	w.WriteString("<script")
 	if len(n.Type) != 0 {
-		a += fmt.Sprintf("%stype=\"%s\"", sep, n.Type)
-		sep = " "
+		writeAttr(w, "type", string(n.Type))
 	}
 	if len(n.Src) != 0 {
-		a += fmt.Sprintf("%ssrc=\"%s\"", sep, n.Src)
-		sep = " "
+		a := d.asset(n.Src)
+		writeAttr(w, "src", a.URL)
+		writeIntegrity(w, a, n.Integrity, n.CrossOrigin)
 	}
 	if n.Defer {
-		a += " defer"
+		writeBoolAttr(w, "defer")
 	}
 	if n.Async {
-		a += " async"
+		writeBoolAttr(w, "async")
 	}
-	return wrap("script", a, n.Content)
+	w.WriteByte('>')
+	w.WriteString(n.Content)
+	writeEndTag(w, "script")
 }
 
+func (n *Script) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *Script) attrs() *Attrs    { return &Attrs{} }
 func (n *Script) bodyVec() BodyVec { return BodyVec{} }
@@ -2,7 +2,7 @@
 package html
 
 import (
-	"fmt"
+	"bufio"
 )
 
 type InlineNode interface {
@@ -12,14 +12,14 @@ type InlineNode interface {
 
 //go:generate gentemplate -d Package=html -id Inline -d Type=InlineNode github.com/platinasystems/elib/vec.tmpl
 
-func (n *InlineVec) Markup(d *Doc) string {
-	s := ""
+func (n *InlineVec) WriteMarkup(w *bufio.Writer, d *Doc) {
 	for _, f := range *n {
-		s += f.Markup(d)
+		f.WriteMarkup(w, d)
 	}
-	return s
 }
 
+func (n *InlineVec) Markup(d *Doc) string { return markup(n, d) }
+
 type inline struct {
 	Attrs
 	X InlineVec
@@ -31,7 +31,7 @@ func (d *Doc) inline(n BodyNode, px *InlineVec, attrs *Attrs, args ...interface{
 	var ls []interface{}
 	for _, a := range args {
 		var isEvent bool
-		if isEvent = isEventListener(a); isEvent {
+		if isEvent = isListenerArg(a); isEvent {
 			ls = append(ls, a)
 		}
 		switch v := a.(type) {
@@ -40,6 +40,13 @@ func (d *Doc) inline(n BodyNode, px *InlineVec, attrs *Attrs, args ...interface{
 				x = append(x, &String{sep + v})
 				sep = " "
 			}
+
+		case Attr:
+			d.setAttr(n, attrs, v)
+
+		case []Attr:
+			d.setAttr(n, attrs, v...)
+
 		case InlineNode:
 			x = append(x, v)
 
@@ -57,15 +64,16 @@ func (d *Doc) inline(n BodyNode, px *InlineVec, attrs *Attrs, args ...interface{
 	}
 
 	if len(ls) > 0 {
-		d.addEventListener(attrs, ls)
+		d.addEventListener(attrs, ls...)
 	}
 
 	*px = x
 }
 
-func (n *inline) markup(d *Doc, tag string) string {
-	a, _ := n.Attrs.String(d)
-	return wrap(tag, a, n.X.Markup(d))
+func (n *inline) write(w *bufio.Writer, d *Doc, tag string) {
+	n.Attrs.writeStartTag(w, d, tag)
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, tag)
 }
 
 func (n *inline) bodyvec() (r BodyVec) {
@@ -85,15 +93,18 @@ func (n *A) bodyNode()   {}
 func (n *A) inlineNode() {}
 func (n *A) node()       {}
 
-func (n *A) Markup(d *Doc) string {
-	a, sep := n.Attrs.String(d)
+func (n *A) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStart(w, d, "a")
 	if len(n.Href) > 0 {
-		a += fmt.Sprintf("%shref=\"%s\"", sep, n.Href)
-		sep = " "
+		writeAttr(w, "href", string(n.Href))
 	}
-	return wrap("a", a, n.X.Markup(d))
+	w.WriteByte('>')
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, "a")
 }
 
+func (n *A) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *A) attrs() *Attrs    { return &n.Attrs }
 func (n *A) bodyVec() BodyVec { return n.inline.bodyvec() }
 
@@ -102,9 +113,8 @@ func (d *Doc) A(args ...interface{}) (n *A) {
 	d.inline(n, &n.X, &n.Attrs, args...)
 	var ok bool
 	var u string
-	if u, ok = n.Attrs.user["href"]; ok {
+	if u, ok = n.Attrs.take("href"); ok {
 		n.Href = URI(u)
-		delete(n.Attrs.user, "href")
 	}
 	return
 }
@@ -115,9 +125,10 @@ func (n *Span) bodyNode()   {}
 func (n *Span) inlineNode() {}
 func (n *Span) node()       {}
 
-func (n *Span) Markup(d *Doc) string { return (*inline)(n).markup(d, "span") }
-func (n *Span) attrs() *Attrs        { return &n.Attrs }
-func (n *Span) bodyVec() BodyVec     { return (*inline)(n).bodyvec() }
+func (n *Span) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "span") }
+func (n *Span) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Span) attrs() *Attrs                       { return &n.Attrs }
+func (n *Span) bodyVec() BodyVec                    { return (*inline)(n).bodyvec() }
 func (d *Doc) Span(args ...interface{}) (n *Span) {
 	n = &Span{}
 	d.inline(n, &n.X, &n.Attrs, args...)
@@ -149,11 +160,17 @@ func (n *Small) bodyNode()   {}
 func (n *Small) inlineNode() {}
 func (n *Small) node()       {}
 
-func (n *TT) Markup(d *Doc) string    { return (*inline)(n).markup(d, "tt") }
-func (n *I) Markup(d *Doc) string     { return (*inline)(n).markup(d, "i") }
-func (n *B) Markup(d *Doc) string     { return (*inline)(n).markup(d, "b") }
-func (n *Big) Markup(d *Doc) string   { return (*inline)(n).markup(d, "big") }
-func (n *Small) Markup(d *Doc) string { return (*inline)(n).markup(d, "small") }
+func (n *TT) WriteMarkup(w *bufio.Writer, d *Doc)    { (*inline)(n).write(w, d, "tt") }
+func (n *I) WriteMarkup(w *bufio.Writer, d *Doc)     { (*inline)(n).write(w, d, "i") }
+func (n *B) WriteMarkup(w *bufio.Writer, d *Doc)     { (*inline)(n).write(w, d, "b") }
+func (n *Big) WriteMarkup(w *bufio.Writer, d *Doc)   { (*inline)(n).write(w, d, "big") }
+func (n *Small) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "small") }
+
+func (n *TT) Markup(d *Doc) string    { return markup(n, d) }
+func (n *I) Markup(d *Doc) string     { return markup(n, d) }
+func (n *B) Markup(d *Doc) string     { return markup(n, d) }
+func (n *Big) Markup(d *Doc) string   { return markup(n, d) }
+func (n *Small) Markup(d *Doc) string { return markup(n, d) }
 
 func (n *TT) attrs() *Attrs    { return &n.Attrs }
 func (n *I) attrs() *Attrs     { return &n.Attrs }
@@ -196,6 +213,233 @@ func (d *Doc) Small(args ...interface{}) (n *Small) {
 // <!ENTITY % phrase "EM | STRONG | DFN | CODE |
 //                    SAMP | KBD | VAR | CITE | ABBR | ACRONYM" >
 
+type Em inline
+type Strong inline
+type Dfn inline
+type Code inline
+type Samp inline
+type Kbd inline
+type Var inline
+type Cite inline
+type Abbr inline
+type Q inline
+type Sub inline
+type Sup inline
+type Mark inline
+
+func (n *Em) bodyNode()       {}
+func (n *Em) inlineNode()     {}
+func (n *Em) node()           {}
+func (n *Strong) bodyNode()   {}
+func (n *Strong) inlineNode() {}
+func (n *Strong) node()       {}
+func (n *Dfn) bodyNode()      {}
+func (n *Dfn) inlineNode()    {}
+func (n *Dfn) node()          {}
+func (n *Code) bodyNode()     {}
+func (n *Code) inlineNode()   {}
+func (n *Code) node()         {}
+func (n *Samp) bodyNode()     {}
+func (n *Samp) inlineNode()   {}
+func (n *Samp) node()         {}
+func (n *Kbd) bodyNode()      {}
+func (n *Kbd) inlineNode()    {}
+func (n *Kbd) node()          {}
+func (n *Var) bodyNode()      {}
+func (n *Var) inlineNode()    {}
+func (n *Var) node()          {}
+func (n *Cite) bodyNode()     {}
+func (n *Cite) inlineNode()   {}
+func (n *Cite) node()         {}
+func (n *Abbr) bodyNode()     {}
+func (n *Abbr) inlineNode()   {}
+func (n *Abbr) node()         {}
+func (n *Q) bodyNode()        {}
+func (n *Q) inlineNode()      {}
+func (n *Q) node()            {}
+func (n *Sub) bodyNode()      {}
+func (n *Sub) inlineNode()    {}
+func (n *Sub) node()          {}
+func (n *Sup) bodyNode()      {}
+func (n *Sup) inlineNode()    {}
+func (n *Sup) node()          {}
+func (n *Mark) bodyNode()     {}
+func (n *Mark) inlineNode()   {}
+func (n *Mark) node()         {}
+
+func (n *Em) WriteMarkup(w *bufio.Writer, d *Doc)     { (*inline)(n).write(w, d, "em") }
+func (n *Strong) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "strong") }
+func (n *Dfn) WriteMarkup(w *bufio.Writer, d *Doc)    { (*inline)(n).write(w, d, "dfn") }
+func (n *Code) WriteMarkup(w *bufio.Writer, d *Doc)   { (*inline)(n).write(w, d, "code") }
+func (n *Samp) WriteMarkup(w *bufio.Writer, d *Doc)   { (*inline)(n).write(w, d, "samp") }
+func (n *Kbd) WriteMarkup(w *bufio.Writer, d *Doc)    { (*inline)(n).write(w, d, "kbd") }
+func (n *Var) WriteMarkup(w *bufio.Writer, d *Doc)    { (*inline)(n).write(w, d, "var") }
+func (n *Cite) WriteMarkup(w *bufio.Writer, d *Doc)   { (*inline)(n).write(w, d, "cite") }
+func (n *Abbr) WriteMarkup(w *bufio.Writer, d *Doc)   { (*inline)(n).write(w, d, "abbr") }
+func (n *Q) WriteMarkup(w *bufio.Writer, d *Doc)      { (*inline)(n).write(w, d, "q") }
+func (n *Sub) WriteMarkup(w *bufio.Writer, d *Doc)    { (*inline)(n).write(w, d, "sub") }
+func (n *Sup) WriteMarkup(w *bufio.Writer, d *Doc)    { (*inline)(n).write(w, d, "sup") }
+func (n *Mark) WriteMarkup(w *bufio.Writer, d *Doc)   { (*inline)(n).write(w, d, "mark") }
+
+func (n *Em) Markup(d *Doc) string     { return markup(n, d) }
+func (n *Strong) Markup(d *Doc) string { return markup(n, d) }
+func (n *Dfn) Markup(d *Doc) string    { return markup(n, d) }
+func (n *Code) Markup(d *Doc) string   { return markup(n, d) }
+func (n *Samp) Markup(d *Doc) string   { return markup(n, d) }
+func (n *Kbd) Markup(d *Doc) string    { return markup(n, d) }
+func (n *Var) Markup(d *Doc) string    { return markup(n, d) }
+func (n *Cite) Markup(d *Doc) string   { return markup(n, d) }
+func (n *Abbr) Markup(d *Doc) string   { return markup(n, d) }
+func (n *Q) Markup(d *Doc) string      { return markup(n, d) }
+func (n *Sub) Markup(d *Doc) string    { return markup(n, d) }
+func (n *Sup) Markup(d *Doc) string    { return markup(n, d) }
+func (n *Mark) Markup(d *Doc) string   { return markup(n, d) }
+
+func (n *Em) attrs() *Attrs     { return &n.Attrs }
+func (n *Strong) attrs() *Attrs { return &n.Attrs }
+func (n *Dfn) attrs() *Attrs    { return &n.Attrs }
+func (n *Code) attrs() *Attrs   { return &n.Attrs }
+func (n *Samp) attrs() *Attrs   { return &n.Attrs }
+func (n *Kbd) attrs() *Attrs    { return &n.Attrs }
+func (n *Var) attrs() *Attrs    { return &n.Attrs }
+func (n *Cite) attrs() *Attrs   { return &n.Attrs }
+func (n *Abbr) attrs() *Attrs   { return &n.Attrs }
+func (n *Q) attrs() *Attrs      { return &n.Attrs }
+func (n *Sub) attrs() *Attrs    { return &n.Attrs }
+func (n *Sup) attrs() *Attrs    { return &n.Attrs }
+func (n *Mark) attrs() *Attrs   { return &n.Attrs }
+
+func (n *Em) bodyVec() BodyVec     { return (*inline)(n).bodyvec() }
+func (n *Strong) bodyVec() BodyVec { return (*inline)(n).bodyvec() }
+func (n *Dfn) bodyVec() BodyVec    { return (*inline)(n).bodyvec() }
+func (n *Code) bodyVec() BodyVec   { return (*inline)(n).bodyvec() }
+func (n *Samp) bodyVec() BodyVec   { return (*inline)(n).bodyvec() }
+func (n *Kbd) bodyVec() BodyVec    { return (*inline)(n).bodyvec() }
+func (n *Var) bodyVec() BodyVec    { return (*inline)(n).bodyvec() }
+func (n *Cite) bodyVec() BodyVec   { return (*inline)(n).bodyvec() }
+func (n *Abbr) bodyVec() BodyVec   { return (*inline)(n).bodyvec() }
+func (n *Q) bodyVec() BodyVec      { return (*inline)(n).bodyvec() }
+func (n *Sub) bodyVec() BodyVec    { return (*inline)(n).bodyvec() }
+func (n *Sup) bodyVec() BodyVec    { return (*inline)(n).bodyvec() }
+func (n *Mark) bodyVec() BodyVec   { return (*inline)(n).bodyvec() }
+
+func (d *Doc) Em(args ...interface{}) (n *Em) {
+	n = &Em{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Strong(args ...interface{}) (n *Strong) {
+	n = &Strong{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Dfn(args ...interface{}) (n *Dfn) {
+	n = &Dfn{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Code(args ...interface{}) (n *Code) {
+	n = &Code{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Samp(args ...interface{}) (n *Samp) {
+	n = &Samp{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Kbd(args ...interface{}) (n *Kbd) {
+	n = &Kbd{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Var(args ...interface{}) (n *Var) {
+	n = &Var{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Cite(args ...interface{}) (n *Cite) {
+	n = &Cite{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Abbr(args ...interface{}) (n *Abbr) {
+	n = &Abbr{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Q(args ...interface{}) (n *Q) {
+	n = &Q{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Sub(args ...interface{}) (n *Sub) {
+	n = &Sub{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Sup(args ...interface{}) (n *Sup) {
+	n = &Sup{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Mark(args ...interface{}) (n *Mark) {
+	n = &Mark{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Date or time with machine readable datetime attribute (e.g. d.Time("datetime=2016-01-02", "Jan 2")).
+type Time inline
+
+func (n *Time) inlineNode() {}
+func (n *Time) bodyNode()   {}
+func (n *Time) node()       {}
+
+func (n *Time) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "time") }
+func (n *Time) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Time) attrs() *Attrs                       { return &n.Attrs }
+func (n *Time) bodyVec() BodyVec                    { return (*inline)(n).bodyvec() }
+func (d *Doc) Time(args ...interface{}) (n *Time) {
+	n = &Time{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Progress bar (e.g. d.Progress("value=70", "max=100")).
+type Progress inline
+
+func (n *Progress) inlineNode() {}
+func (n *Progress) bodyNode()   {}
+func (n *Progress) node()       {}
+
+func (n *Progress) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "progress") }
+func (n *Progress) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Progress) attrs() *Attrs                       { return &n.Attrs }
+func (n *Progress) bodyVec() BodyVec                    { return (*inline)(n).bodyvec() }
+func (d *Doc) Progress(args ...interface{}) (n *Progress) {
+	n = &Progress{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Scalar measurement within known range (e.g. d.Meter("value=0.6", "low=0.2", "high=0.8")).
+type Meter inline
+
+func (n *Meter) inlineNode() {}
+func (n *Meter) bodyNode()   {}
+func (n *Meter) node()       {}
+
+func (n *Meter) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "meter") }
+func (n *Meter) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Meter) attrs() *Attrs                       { return &n.Attrs }
+func (n *Meter) bodyVec() BodyVec                    { return (*inline)(n).bodyvec() }
+func (d *Doc) Meter(args ...interface{}) (n *Meter) {
+	n = &Meter{}
+	d.inline(n, &n.X, &n.Attrs, args...)
+	return
+}
+
 // Forced line break.
 type BR Attrs
 
@@ -203,9 +447,10 @@ func (n *BR) bodyNode()   {}
 func (n *BR) inlineNode() {}
 func (n *BR) node()       {}
 
-func (n *BR) Markup(d *Doc) string { return (*Attrs)(n).markup(d, "br") }
-func (n *BR) attrs() *Attrs        { return (*Attrs)(n) }
-func (n *BR) bodyVec() BodyVec     { return BodyVec{} }
+func (n *BR) WriteMarkup(w *bufio.Writer, d *Doc) { (*Attrs)(n).writeStartTag(w, d, "br") }
+func (n *BR) Markup(d *Doc) string                { return markup(n, d) }
+func (n *BR) attrs() *Attrs                       { return (*Attrs)(n) }
+func (n *BR) bodyVec() BodyVec                    { return BodyVec{} }
 func (d *Doc) BR(args ...interface{}) (n *BR) {
 	n = &BR{}
 	d.attrsOnly(n, (*Attrs)(n), args...)
@@ -0,0 +1,123 @@
+// HTML5 embedded content: images, audio, video and frames.
+package html
+
+import (
+	"bufio"
+)
+
+// Image (e.g. d.Img("src=/img/logo.png", "alt=Logo")).
+type Img Attrs
+
+func (n *Img) bodyNode()   {}
+func (n *Img) inlineNode() {}
+func (n *Img) node()       {}
+
+func (n *Img) WriteMarkup(w *bufio.Writer, d *Doc) { (*Attrs)(n).writeStartTag(w, d, "img") }
+func (n *Img) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Img) attrs() *Attrs                       { return (*Attrs)(n) }
+func (n *Img) bodyVec() BodyVec                    { return BodyVec{} }
+func (d *Doc) Img(args ...interface{}) (n *Img) {
+	n = &Img{}
+	d.attrsForce(n, (*Attrs)(n), args...)
+	return
+}
+
+// Media resource for Picture, Video and Audio (e.g. d.Source("src=/a.webm", "type=video/webm")).
+type Source Attrs
+
+// Timed text track (e.g. subtitles) for Video and Audio.
+type Track Attrs
+
+func (n *Source) bodyNode()   {}
+func (n *Source) inlineNode() {}
+func (n *Source) node()       {}
+func (n *Track) bodyNode()    {}
+func (n *Track) inlineNode()  {}
+func (n *Track) node()        {}
+
+func (n *Source) WriteMarkup(w *bufio.Writer, d *Doc) { (*Attrs)(n).writeStartTag(w, d, "source") }
+func (n *Track) WriteMarkup(w *bufio.Writer, d *Doc)  { (*Attrs)(n).writeStartTag(w, d, "track") }
+
+func (n *Source) Markup(d *Doc) string { return markup(n, d) }
+func (n *Track) Markup(d *Doc) string  { return markup(n, d) }
+
+func (n *Source) attrs() *Attrs { return (*Attrs)(n) }
+func (n *Track) attrs() *Attrs  { return (*Attrs)(n) }
+
+func (n *Source) bodyVec() BodyVec { return BodyVec{} }
+func (n *Track) bodyVec() BodyVec  { return BodyVec{} }
+
+func (d *Doc) Source(args ...interface{}) (n *Source) {
+	n = &Source{}
+	d.attrsForce(n, (*Attrs)(n), args...)
+	return
+}
+func (d *Doc) Track(args ...interface{}) (n *Track) {
+	n = &Track{}
+	d.attrsForce(n, (*Attrs)(n), args...)
+	return
+}
+
+// Picture, Video and Audio contain Source (and Track) elements followed by fallback content.
+type Picture Flow
+type Video Flow
+type Audio Flow
+
+func (n *Picture) bodyNode()   {}
+func (n *Picture) inlineNode() {}
+func (n *Picture) node()       {}
+func (n *Video) bodyNode()     {}
+func (n *Video) inlineNode()   {}
+func (n *Video) node()         {}
+func (n *Audio) bodyNode()     {}
+func (n *Audio) inlineNode()   {}
+func (n *Audio) node()         {}
+
+func (n *Picture) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "picture") }
+func (n *Video) WriteMarkup(w *bufio.Writer, d *Doc)   { (*Flow)(n).write(w, d, "video") }
+func (n *Audio) WriteMarkup(w *bufio.Writer, d *Doc)   { (*Flow)(n).write(w, d, "audio") }
+
+func (n *Picture) Markup(d *Doc) string { return markup(n, d) }
+func (n *Video) Markup(d *Doc) string   { return markup(n, d) }
+func (n *Audio) Markup(d *Doc) string   { return markup(n, d) }
+
+func (n *Picture) attrs() *Attrs { return &n.Attrs }
+func (n *Video) attrs() *Attrs   { return &n.Attrs }
+func (n *Audio) attrs() *Attrs   { return &n.Attrs }
+
+func (n *Picture) bodyVec() BodyVec { return (*Flow)(n).bodyvec() }
+func (n *Video) bodyVec() BodyVec   { return (*Flow)(n).bodyvec() }
+func (n *Audio) bodyVec() BodyVec   { return (*Flow)(n).bodyvec() }
+
+func (d *Doc) Picture(args ...interface{}) (n *Picture) {
+	n = &Picture{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Video(args ...interface{}) (n *Video) {
+	n = &Video{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+func (d *Doc) Audio(args ...interface{}) (n *Audio) {
+	n = &Audio{}
+	d.flow(n, &n.X, &n.Attrs, args...)
+	return
+}
+
+// Nested browsing context (e.g. d.Iframe("src=/status", "width=100%")).
+type Iframe Attrs
+
+func (n *Iframe) bodyNode()   {}
+func (n *Iframe) inlineNode() {}
+func (n *Iframe) node()       {}
+
+func (n *Iframe) WriteMarkup(w *bufio.Writer, d *Doc) { (*Attrs)(n).writeEndTag(w, d, "iframe") }
+func (n *Iframe) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Iframe) attrs() *Attrs                       { return (*Attrs)(n) }
+func (n *Iframe) bodyVec() BodyVec                    { return BodyVec{} }
+func (d *Doc) Iframe(args ...interface{}) (n *Iframe) {
+	n = &Iframe{}
+	d.attrsForce(n, (*Attrs)(n), args...)
+	return
+}
@@ -0,0 +1,46 @@
+package html
+
+import "testing"
+
+func TestHTML5Elements(t *testing.T) {
+	d := &Doc{}
+	for _, c := range []struct {
+		n    Node
+		want string
+	}{
+		{d.Article(d.Header(d.H1("T")), d.P("x"), d.Footer("f")), `<article><header><h1>T</h1></header><p>x</p><footer>f</footer></article>`},
+		{d.Details(d.Summary("more"), d.P("x")), `<details><summary>more</summary><p>x</p></details>`},
+		{d.Figure(d.Img("src=/a.png", `alt="A b"`), d.Figcaption("cap")), `<figure><img src="/a.png" alt="A b"><figcaption>cap</figcaption></figure>`},
+		{d.Video("controls=", d.Source("src=/a.webm", "type=video/webm"), d.Track("kind=captions", "default")),
+			`<video controls><source src="/a.webm" type="video/webm"><track kind="captions" default></video>`},
+		{d.Iframe("src=/s", "allowfullscreen"), `<iframe src="/s" allowfullscreen></iframe>`},
+		{d.P(d.Em("a"), d.Strong("b"), d.Code("c"), d.Kbd("k"), d.Mark("m")), `<p><em>a</em><strong>b</strong><code>c</code><kbd>k</kbd><mark>m</mark></p>`},
+		{d.Abbr("title=Link Aggregation", "LAG"), `<abbr title="Link Aggregation">LAG</abbr>`},
+		{d.Progress("value=3", "max=10"), `<progress value="3" max="10"></progress>`},
+		{d.Meter("value=0.5", "50%"), `<meter value="0.5">50%</meter>`},
+		{d.Time("datetime=2024-01-02", "Jan 2"), `<time datetime="2024-01-02">Jan 2</time>`},
+	} {
+		if got := c.n.Markup(d); got != c.want {
+			t.Errorf("%T: got %s want %s", c.n, got, c.want)
+		}
+	}
+}
+
+// Elements without content panic on text rather than dropping it.
+func TestVoidElementText(t *testing.T) {
+	d := &Doc{}
+	for _, f := range []func(){
+		func() { d.Img("src=/a.png", "a picture") },
+		func() { d.Source("1+1=2") },
+		func() { d.Iframe(42) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -1,5 +1,9 @@
 package html
 
+import (
+	"bufio"
+)
+
 type svgNode interface {
 	BodyNode
 	svgNode()
@@ -7,14 +11,14 @@ type svgNode interface {
 
 //go:generate gentemplate -d Package=html -id svgNode -d Type=svgNode github.com/platinasystems/elib/vec.tmpl
 
-func (n *svgNodeVec) Markup(d *Doc) string {
-	s := ""
+func (n *svgNodeVec) WriteMarkup(w *bufio.Writer, d *Doc) {
 	for _, f := range *n {
-		s += f.Markup(d)
+		f.WriteMarkup(w, d)
 	}
-	return s
 }
 
+func (n *svgNodeVec) Markup(d *Doc) string { return markup(n, d) }
+
 type Svg struct {
 	Attrs
 	X svgNodeVec
@@ -24,11 +28,14 @@ func (n *Svg) blockNode() {}
 func (n *Svg) bodyNode()  {}
 func (n *Svg) node()      {}
 
-func (n *Svg) Markup(d *Doc) string {
-	a, _ := n.Attrs.String(d)
-	return wrap("svg", a, n.X.Markup(d))
+func (n *Svg) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStartTag(w, d, "svg")
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, "svg")
 }
 
+func (n *Svg) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *Svg) attrs() *Attrs { return &n.Attrs }
 
 func (n *Svg) bodyVec() (r BodyVec) {
@@ -46,6 +53,12 @@ func (d *Doc) Svg(args ...interface{}) *Svg {
 		case string:
 			d.addAttrForce(n, &n.Attrs, v)
 
+		case Attr:
+			d.setAttr(n, &n.Attrs, v)
+
+		case []Attr:
+			d.setAttr(n, &n.Attrs, v...)
+
 		case svgNode:
 			n.X = append(n.X, v)
 
@@ -62,7 +75,7 @@ func (d *Doc) Svg(args ...interface{}) *Svg {
 	}
 
 	if len(ls) > 0 {
-		d.addEventListener(&n.Attrs, ls)
+		d.addEventListener(&n.Attrs, ls...)
 	}
 
 	return n
@@ -74,13 +87,12 @@ func (n *Circle) svgNode()  {}
 func (n *Circle) bodyNode() {}
 func (n *Circle) node()     {}
 
-func (n *Circle) Markup(d *Doc) string { return (*Attrs)(n).markup(d, "circle") }
-func (n *Circle) attrs() *Attrs        { return (*Attrs)(n) }
-func (n *Circle) bodyVec() BodyVec     { return BodyVec{} }
-func (d *Doc) Circle(args ...string) *Circle {
+func (n *Circle) WriteMarkup(w *bufio.Writer, d *Doc) { (*Attrs)(n).writeStartTag(w, d, "circle") }
+func (n *Circle) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Circle) attrs() *Attrs                       { return (*Attrs)(n) }
+func (n *Circle) bodyVec() BodyVec                    { return BodyVec{} }
+func (d *Doc) Circle(args ...interface{}) *Circle {
 	n := &Circle{}
-	for _, a := range args {
-		d.addAttrForce(n, (*Attrs)(n), a)
-	}
+	d.attrsForce(n, (*Attrs)(n), args...)
 	return n
 }
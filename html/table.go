@@ -1,5 +1,9 @@
 package html
 
+import (
+	"bufio"
+)
+
 type tableNode interface {
 	BodyNode
 	tableNode()
@@ -7,14 +11,14 @@ type tableNode interface {
 
 //go:generate gentemplate -d Package=html -id tableNode -d Type=tableNode github.com/platinasystems/elib/vec.tmpl
 
-func (n *tableNodeVec) Markup(d *Doc) string {
-	s := ""
+func (n *tableNodeVec) WriteMarkup(w *bufio.Writer, d *Doc) {
 	for _, f := range *n {
-		s += f.Markup(d)
+		f.WriteMarkup(w, d)
 	}
-	return s
 }
 
+func (n *tableNodeVec) Markup(d *Doc) string { return markup(n, d) }
+
 type Table struct {
 	Attrs
 	X tableNodeVec
@@ -24,11 +28,14 @@ func (n *Table) blockNode() {}
 func (n *Table) bodyNode()  {}
 func (n *Table) node()      {}
 
-func (n *Table) Markup(d *Doc) string {
-	a, _ := n.Attrs.String(d)
-	return wrap("table", a, n.X.Markup(d))
+func (n *Table) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStartTag(w, d, "table")
+	n.X.WriteMarkup(w, d)
+	writeEndTag(w, "table")
 }
 
+func (n *Table) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *Table) attrs() *Attrs { return &n.Attrs }
 
 func (n *Table) bodyVec() (r BodyVec) {
@@ -45,6 +52,12 @@ func (d *Doc) Table(args ...interface{}) *Table {
 		case string:
 			d.addAttrForce(n, &n.Attrs, v)
 
+		case Attr:
+			d.setAttr(n, &n.Attrs, v)
+
+		case []Attr:
+			d.setAttr(n, &n.Attrs, v...)
+
 		case tableNode:
 			n.X = append(n.X, v)
 
@@ -65,9 +78,10 @@ func (n *Caption) tableNode() {}
 func (n *Caption) bodyNode()  {}
 func (n *Caption) node()      {}
 
-func (n *Caption) Markup(d *Doc) string { return (*inline)(n).markup(d, "caption") }
-func (n *Caption) attrs() *Attrs        { return &n.Attrs }
-func (n *Caption) bodyVec() BodyVec     { return (*inline)(n).bodyvec() }
+func (n *Caption) WriteMarkup(w *bufio.Writer, d *Doc) { (*inline)(n).write(w, d, "caption") }
+func (n *Caption) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Caption) attrs() *Attrs                       { return &n.Attrs }
+func (n *Caption) bodyVec() BodyVec                    { return (*inline)(n).bodyvec() }
 func (d *Doc) Caption(args ...interface{}) (n *Caption) {
 	n = &Caption{}
 	d.inline(n, &n.X, &n.Attrs, args...)
@@ -83,15 +97,16 @@ func (n *Colgroup) tableNode() {}
 func (n *Colgroup) bodyNode()  {}
 func (n *Colgroup) node()      {}
 
-func (n *Colgroup) Markup(d *Doc) string {
-	a, _ := n.Attrs.String(d)
-	s := ""
-	for _, f := range n.X {
-		s += f.Markup(d)
+func (n *Colgroup) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStartTag(w, d, "colgroup")
+	for i := range n.X {
+		n.X[i].WriteMarkup(w, d)
 	}
-	return wrap("colgroup", a, s)
+	writeEndTag(w, "colgroup")
 }
 
+func (n *Colgroup) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *Colgroup) attrs() *Attrs { return &n.Attrs }
 func (n *Colgroup) bodyVec() (r BodyVec) {
 	for i := range n.X {
@@ -125,14 +140,13 @@ type Col Attrs
 func (c *Col) node()     {}
 func (c *Col) bodyNode() {}
 
-func (n *Col) Markup(d *Doc) string { return (*Attrs)(n).markup(d, "col") }
-func (n *Col) attrs() *Attrs        { return (*Attrs)(n) }
-func (n *Col) bodyVec() BodyVec     { return BodyVec{} }
-func (d *Doc) Col(args ...string) *Col {
+func (n *Col) WriteMarkup(w *bufio.Writer, d *Doc) { (*Attrs)(n).writeStartTag(w, d, "col") }
+func (n *Col) Markup(d *Doc) string                { return markup(n, d) }
+func (n *Col) attrs() *Attrs                       { return (*Attrs)(n) }
+func (n *Col) bodyVec() BodyVec                    { return BodyVec{} }
+func (d *Doc) Col(args ...interface{}) *Col {
 	n := &Col{}
-	for _, a := range args {
-		d.addAttrForce(n, (*Attrs)(n), a)
-	}
+	d.attrsForce(n, (*Attrs)(n), args...)
 	return n
 }
 
@@ -143,13 +157,12 @@ type tableRows struct {
 	X tableRowVec
 }
 
-func (n *tableRows) markup(d *Doc, tag string) string {
-	a, _ := n.Attrs.String(d)
-	s := ""
-	for _, f := range n.X {
-		s += f.Markup(d)
+func (n *tableRows) write(w *bufio.Writer, d *Doc, tag string) {
+	n.Attrs.writeStartTag(w, d, tag)
+	for i := range n.X {
+		n.X[i].WriteMarkup(w, d)
 	}
-	return wrap(tag, a, s)
+	writeEndTag(w, tag)
 }
 
 func (n *tableRows) bodyvec() (r BodyVec) {
@@ -166,6 +179,12 @@ func (d *Doc) tableRows(n BodyNode, px *tableRowVec, attrs *Attrs, args ...inter
 		case string:
 			d.addAttrForce(n, attrs, v)
 
+		case Attr:
+			d.setAttr(n, attrs, v)
+
+		case []Attr:
+			d.setAttr(n, attrs, v...)
+
 		case TR:
 			x = append(x, v)
 
@@ -210,9 +229,13 @@ func (n *Tfoot) tableNode() {}
 func (n *Tfoot) bodyNode()  {}
 func (n *Tfoot) node()      {}
 
-func (n *Thead) Markup(d *Doc) string { return (*tableRows)(n).markup(d, "thead") }
-func (n *Tbody) Markup(d *Doc) string { return (*tableRows)(n).markup(d, "tbody") }
-func (n *Tfoot) Markup(d *Doc) string { return (*tableRows)(n).markup(d, "tfoot") }
+func (n *Thead) WriteMarkup(w *bufio.Writer, d *Doc) { (*tableRows)(n).write(w, d, "thead") }
+func (n *Tbody) WriteMarkup(w *bufio.Writer, d *Doc) { (*tableRows)(n).write(w, d, "tbody") }
+func (n *Tfoot) WriteMarkup(w *bufio.Writer, d *Doc) { (*tableRows)(n).write(w, d, "tfoot") }
+
+func (n *Thead) Markup(d *Doc) string { return markup(n, d) }
+func (n *Tbody) Markup(d *Doc) string { return markup(n, d) }
+func (n *Tfoot) Markup(d *Doc) string { return markup(n, d) }
 
 func (n *Thead) attrs() *Attrs { return &n.Attrs }
 func (n *Tbody) attrs() *Attrs { return &n.Attrs }
@@ -242,15 +265,16 @@ func (n *TR) tableRowNode() {}
 func (n *TR) bodyNode()     {}
 func (n *TR) node()         {}
 
-func (n *TR) Markup(d *Doc) string {
-	a, _ := n.Attrs.String(d)
-	s := ""
+func (n *TR) WriteMarkup(w *bufio.Writer, d *Doc) {
+	n.Attrs.writeStartTag(w, d, "tr")
 	for _, f := range n.X {
-		s += f.Markup(d)
+		f.WriteMarkup(w, d)
 	}
-	return wrap("tr", a, s)
+	writeEndTag(w, "tr")
 }
 
+func (n *TR) Markup(d *Doc) string { return markup(n, d) }
+
 func (n *TR) attrs() *Attrs { return &n.Attrs }
 func (n *TR) bodyVec() (r BodyVec) {
 	for i := range n.X {
@@ -266,6 +290,12 @@ func (d *Doc) TR(args ...interface{}) *TR {
 		case string:
 			d.addAttrForce(n, &n.Attrs, v)
 
+		case Attr:
+			d.setAttr(n, &n.Attrs, v)
+
+		case []Attr:
+			d.setAttr(n, &n.Attrs, v...)
+
 		case tableRowNode:
 			n.X = append(n.X, v)
 
@@ -292,8 +322,11 @@ func (n *TD) tableRowNode() {}
 func (n *TD) bodyNode()     {}
 func (n *TD) node()         {}
 
-func (n *TH) Markup(d *Doc) string { return (*Flow)(n).markup(d, "th") }
-func (n *TD) Markup(d *Doc) string { return (*Flow)(n).markup(d, "td") }
+func (n *TH) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "th") }
+func (n *TD) WriteMarkup(w *bufio.Writer, d *Doc) { (*Flow)(n).write(w, d, "td") }
+
+func (n *TH) Markup(d *Doc) string { return markup(n, d) }
+func (n *TD) Markup(d *Doc) string { return markup(n, d) }
 
 func (n *TH) attrs() *Attrs { return &n.Attrs }
 func (n *TD) attrs() *Attrs { return &n.Attrs }
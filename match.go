@@ -0,0 +1,184 @@
+package weeb
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/platinasystems/weeb/html"
+)
+
+// Parameters captured from URL path by page pattern.
+// For example, pattern /port/{name}/stats matching /port/eth0/stats gives name=eth0 and
+// pattern /files/*rest matching /files/a/b.txt gives rest=a/b.txt.
+type Params map[string]string
+
+// Optional interface for pages whose patterns capture parameters.
+// PageBodyParams is called instead of PageBody.
+type ParamsPage interface {
+	Page
+	PageBodyParams(path string, params Params, d *html.Doc) html.BodyVec
+}
+
+// Kinds of pattern segments in order of increasing precedence.
+const (
+	segWildcard = iota // *NAME or trailing slash: matches rest of path
+	segParam           // {NAME}: matches any single non-empty segment
+	segLiteral
+)
+
+type segment struct {
+	kind int
+	// Literal text or parameter name.
+	s string
+}
+
+// Page pattern parsed into segments.
+type route struct {
+	pattern string
+	segs    []segment
+	page    Page
+}
+
+// Parse pattern into segments.  Pattern ending in slash matches any path with pattern as prefix.
+// Wildcard *NAME must be the final segment.
+func parsePattern(pattern string) (segs []segment, err error) {
+	p := strings.TrimPrefix(pattern, "/")
+	prefix := strings.HasSuffix(p, "/") || len(p) == 0
+	p = strings.TrimSuffix(p, "/")
+	if len(p) > 0 {
+		for _, x := range strings.Split(p, "/") {
+			if len(segs) > 0 && segs[len(segs)-1].kind == segWildcard {
+				return nil, fmt.Errorf("weeb: wildcard not final segment of pattern %s", pattern)
+			}
+			n := len(x)
+			switch {
+			case n > 2 && x[0] == '{' && x[n-1] == '}':
+				segs = append(segs, segment{kind: segParam, s: x[1 : n-1]})
+			case n > 0 && x[0] == '*':
+				segs = append(segs, segment{kind: segWildcard, s: x[1:]})
+			default:
+				segs = append(segs, segment{kind: segLiteral, s: x})
+			}
+		}
+	}
+	if prefix && (len(segs) == 0 || segs[len(segs)-1].kind != segWildcard) {
+		segs = append(segs, segment{kind: segWildcard})
+	}
+	return
+}
+
+// Match path (already cleaned) against pattern segments returning captured parameters.
+func matchSegments(segs []segment, path string) (params Params, ok bool) {
+	ps := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, s := range segs {
+		if s.kind == segWildcard {
+			// Trailing slash pattern requires something (possibly empty) after the slash.
+			if len(s.s) == 0 && i >= len(ps) {
+				return
+			}
+			if len(s.s) > 0 {
+				if params == nil {
+					params = make(Params)
+				}
+				if i < len(ps) {
+					params[s.s] = strings.Join(ps[i:], "/")
+				} else {
+					params[s.s] = ""
+				}
+			}
+			ok = true
+			return
+		}
+		if i >= len(ps) {
+			return
+		}
+		switch s.kind {
+		case segLiteral:
+			if ps[i] != s.s {
+				return
+			}
+		case segParam:
+			if len(ps[i]) == 0 {
+				return
+			}
+			if params == nil {
+				params = make(Params)
+			}
+			params[s.s] = ps[i]
+		}
+	}
+	ok = len(ps) == len(segs)
+	return
+}
+
+// Does pattern a take precedence over pattern b?  Segments are compared left to right with
+// literals before parameters before wildcards; equally specific patterns are ordered lexically.
+func morePrecise(a string, as []segment, b string, bs []segment) bool {
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i].kind != bs[i].kind {
+			return as[i].kind > bs[i].kind
+		}
+	}
+	if len(as) != len(bs) {
+		return len(as) > len(bs)
+	}
+	return a < b
+}
+
+// Parse and validate page patterns into table ordered by precedence.  Called on first match
+// if not called when site is set up; must be called again after PageByPath is changed.
+// Pages with invalid patterns are left out of table and the first such pattern is returned as error.
+func (s *Site) InitPatterns() (err error) {
+	var pats []string
+	for pat := range s.PageByPath {
+		pats = append(pats, pat)
+	}
+	sort.Strings(pats)
+	rs := make([]route, 0, len(pats))
+	for _, pat := range pats {
+		segs, e := parsePattern(pat)
+		if e != nil {
+			if err == nil {
+				err = e
+			}
+			continue
+		}
+		rs = append(rs, route{pattern: pat, segs: segs, page: s.PageByPath[pat]})
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		return morePrecise(rs[i].pattern, rs[i].segs, rs[j].pattern, rs[j].segs)
+	})
+	s.mutex.Lock()
+	s.routes = rs
+	s.mutex.Unlock()
+	return
+}
+
+// Page pattern table; built on first use with invalid patterns logged.
+func (s *Site) patterns() []route {
+	s.mutex.Lock()
+	rs := s.routes
+	s.mutex.Unlock()
+	if rs == nil {
+		if err := s.InitPatterns(); err != nil {
+			log.Print(err)
+		}
+		s.mutex.Lock()
+		rs = s.routes
+		s.mutex.Unlock()
+	}
+	return rs
+}
+
+// Find page with most specific pattern matching path along with parameters captured by pattern.
+func (s *Site) MatchParams(key string) (p Page, params Params, pattern string) {
+	key = cleanPath(key)
+	for _, r := range s.patterns() {
+		if ps, ok := matchSegments(r.segs, key); ok {
+			return r.page, ps, r.pattern
+		}
+	}
+	return
+}
@@ -0,0 +1,115 @@
+package weeb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/platinasystems/weeb/html"
+)
+
+type patternPage string
+
+func (p patternPage) PageBody(path string, d *html.Doc) html.BodyVec { return nil }
+
+func TestMatchParams(t *testing.T) {
+	s := &Site{PageByPath: make(map[string]Page)}
+	for _, p := range []string{
+		"/", "/page/", "/exec", "/a/",
+		"/port/{name}/stats", "/port/{name}/", "/port/eth0/stats",
+		"/files/*rest", "/{a}/x", "/{b}/x",
+	} {
+		s.PageByPath[p] = patternPage(p)
+	}
+	for _, c := range []struct {
+		path, pattern string
+		params        Params
+	}{
+		{"/", "/", nil},
+		{"/exec", "/exec", nil},
+		{"/exec/", "/", nil},
+		{"/page", "/", nil},
+		{"/page/", "/page/", nil},
+		{"/page/a/b", "/page/", nil},
+		{"/page/../exec", "/exec", nil},
+		// Literal segments before parameters before wildcards.
+		{"/port/eth0/stats", "/port/eth0/stats", nil},
+		{"/port/eth1/stats", "/port/{name}/stats", Params{"name": "eth1"}},
+		{"/port/eth1/foo", "/port/{name}/", Params{"name": "eth1"}},
+		// Parameters match only non-empty segments.
+		{"/port/eth1", "/", nil},
+		{"/port//stats", "/", nil},
+		{"/files", "/files/*rest", Params{"rest": ""}},
+		{"/files/a/b.txt", "/files/*rest", Params{"rest": "a/b.txt"}},
+		// Equally specific patterns are ordered lexically.
+		{"/q/x", "/{a}/x", Params{"a": "q"}},
+		// Leftmost segment decides.
+		{"/a/x", "/a/", nil},
+	} {
+		p, params, pattern := s.MatchParams(c.path)
+		if pattern != c.pattern || p != patternPage(c.pattern) {
+			t.Errorf("%s: got pattern %q want %q", c.path, pattern, c.pattern)
+			continue
+		}
+		if len(params) != 0 || len(c.params) != 0 {
+			if !reflect.DeepEqual(params, c.params) {
+				t.Errorf("%s: got params %v want %v", c.path, params, c.params)
+			}
+		}
+	}
+}
+
+func TestMatchNone(t *testing.T) {
+	s := &Site{PageByPath: map[string]Page{"/x": patternPage("/x")}}
+	if p, _, pattern := s.MatchParams("/y"); p != nil || pattern != "" {
+		t.Errorf("got %q want no match", pattern)
+	}
+}
+
+// Wildcard must be the final segment of a pattern.
+func TestParsePatternWildcard(t *testing.T) {
+	for _, p := range []string{"/files/*rest", "/files/*rest/", "/*"} {
+		if segs, err := parsePattern(p); err != nil || segs[len(segs)-1].kind != segWildcard {
+			t.Errorf("%s: got %v %v", p, segs, err)
+		}
+	}
+	for _, p := range []string{"/files/*rest/edit", "/*/x/", "/a/*b/{c}"} {
+		if _, err := parsePattern(p); err == nil {
+			t.Errorf("%s: expected error", p)
+		}
+	}
+}
+
+// Invalid patterns are reported when set up and left out rather than failing requests.
+func TestInitPatterns(t *testing.T) {
+	s := &Site{PageByPath: map[string]Page{
+		"/x":          patternPage("/x"),
+		"/a/*b/c":     patternPage("/a/*b/c"),
+		"/files/*r/x": patternPage("/files/*r/x"),
+	}}
+	err := s.InitPatterns()
+	if err == nil || err.Error() != "weeb: wildcard not final segment of pattern /a/*b/c" {
+		t.Errorf("got error %v", err)
+	}
+	if _, _, pattern := s.MatchParams("/x"); pattern != "/x" {
+		t.Errorf("got %q want /x", pattern)
+	}
+	if p, _, _ := s.MatchParams("/a/q/c"); p != nil {
+		t.Errorf("invalid pattern matched")
+	}
+
+	// Table is built on first match when InitPatterns is not called.
+	s = &Site{PageByPath: map[string]Page{"/a/*b/c": patternPage("/a/*b/c"), "/": patternPage("/")}}
+	if _, _, pattern := s.MatchParams("/a/q/c"); pattern != "/" {
+		t.Errorf("got %q want /", pattern)
+	}
+
+	// Table is rebuilt by InitPatterns after pages change.
+	s.PageByPath["/y"] = patternPage("/y")
+	if _, _, pattern := s.MatchParams("/y"); pattern != "/" {
+		t.Errorf("got %q before InitPatterns want /", pattern)
+	}
+	s.InitPatterns()
+	if _, _, pattern := s.MatchParams("/y"); pattern != "/y" {
+		t.Errorf("got %q want /y", pattern)
+	}
+}
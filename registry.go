@@ -0,0 +1,237 @@
+package weeb
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/platinasystems/weeb/html"
+)
+
+// Content by URL path safe for concurrent use.  Zero value is an empty registry.
+// Registries may be mounted in other registries under URL path prefixes.
+type ContentRegistry struct {
+	mutex  sync.RWMutex
+	byPath map[string]*Content
+	// Fingerprinted content by logical name.
+	byName map[string]*Content
+	// Assets without content (e.g. from weebgen manifests in gopherjs clients) by logical name.
+	assets html.AssetMap
+	// Mounted registries; longest prefix first.  Replaced rather than modified in place
+	// so that readers may recurse into mounts after releasing mutex.
+	mounts []contentMount
+}
+
+type contentMount struct {
+	prefix string
+	r      *ContentRegistry
+}
+
+// Registry used by Content.Register (and so by weebgen generated code) and by sites by default.
+var DefaultContentRegistry = &ContentRegistry{}
+
+// Add content at its URL path.  It is an error to add content with an already registered path.
+func (r *ContentRegistry) Add(c *Content) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.byPath[c.URLPath]; ok {
+		return fmt.Errorf("weeb: content %s already registered", c.URLPath)
+	}
+	if _, ok := r.byName[c.Name]; ok && len(c.Name) > 0 {
+		return fmt.Errorf("weeb: content named %s already registered", c.Name)
+	}
+	if r.byPath == nil {
+		r.byPath = make(map[string]*Content)
+	}
+	r.byPath[c.URLPath] = c
+	if len(c.Name) > 0 {
+		if r.byName == nil {
+			r.byName = make(map[string]*Content)
+		}
+		r.byName[c.Name] = c
+	}
+	return nil
+}
+
+// Add content; panic if path is already registered.
+func (r *ContentRegistry) MustAdd(c *Content) {
+	if err := r.Add(c); err != nil {
+		panic(err)
+	}
+}
+
+// Remove content with given URL path.  Returns false if not found.
+func (r *ContentRegistry) Remove(path string) (ok bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var c *Content
+	if c, ok = r.byPath[path]; ok {
+		delete(r.byPath, path)
+		if len(c.Name) > 0 {
+			delete(r.byName, c.Name)
+		}
+	}
+	return
+}
+
+// Find content for URL path in this registry or else in registries mounted under path prefixes.
+func (r *ContentRegistry) Lookup(path string) (c *Content, ok bool) {
+	r.mutex.RLock()
+	c, ok = r.byPath[path]
+	mounts := r.mounts
+	r.mutex.RUnlock()
+	if ok {
+		return
+	}
+	for _, m := range mounts {
+		if strings.HasPrefix(path, m.prefix+"/") {
+			if c, ok = m.r.Lookup(path[len(m.prefix):]); ok {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Resolve logical asset name to URL path of fingerprinted content and its integrity digest.  Names of content which is not
+// fingerprinted are their URL paths.
+func (r *ContentRegistry) ResolveAsset(name string) (a html.Asset, ok bool) {
+	r.mutex.RLock()
+	c, ok := r.byName[name]
+	if !ok {
+		c, ok = r.byPath[name]
+	}
+	if ok {
+		a.URL, a.Integrity = c.URLPath, c.Integrity
+	} else {
+		a, ok = r.assets[name]
+	}
+	mounts := r.mounts
+	r.mutex.RUnlock()
+	if ok {
+		return
+	}
+	for _, m := range mounts {
+		if strings.HasPrefix(name, m.prefix+"/") {
+			if a, ok = m.r.ResolveAsset(name[len(m.prefix):]); ok {
+				a.URL = m.prefix + a.URL
+				return
+			}
+		}
+	}
+	return
+}
+
+// Add assets by logical name for resolving names of content which is not in registry (e.g. in gopherjs
+// clients where generated content is not built).  Registered content takes precedence.
+func (r *ContentRegistry) AddAssets(m html.AssetMap) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.assets == nil {
+		r.assets = make(html.AssetMap)
+	}
+	for name, a := range m {
+		r.assets[name] = a
+	}
+}
+
+// Add assets to default registry.  Called by manifests generated by weebgen -fingerprint or -integrity.
+func RegisterAssets(m html.AssetMap) {
+	DefaultContentRegistry.AddAssets(m)
+}
+
+// Sorted URL paths of all content including mounted registries.
+func (r *ContentRegistry) Paths() (ps []string) {
+	r.mutex.RLock()
+	for p := range r.byPath {
+		ps = append(ps, p)
+	}
+	mounts := r.mounts
+	r.mutex.RUnlock()
+	for _, m := range mounts {
+		for _, p := range m.r.Paths() {
+			ps = append(ps, m.prefix+p)
+		}
+	}
+	sort.Strings(ps)
+	return
+}
+
+// Mount registry so that its content with path P is found at path prefix+P (e.g. prefix /static).
+// Mounting at an already mounted prefix replaces previous registry.  It is an error to mount
+// a registry in itself or in a registry mounted (directly or indirectly) in it.
+func (r *ContentRegistry) Mount(prefix string, sub *ContentRegistry) error {
+	prefix = strings.TrimSuffix(cleanPath(prefix), "/")
+	if sub.reaches(r) {
+		return fmt.Errorf("weeb: mount at %s would create cycle", prefix)
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	mounts := make([]contentMount, 0, len(r.mounts)+1)
+	for _, m := range r.mounts {
+		if m.prefix != prefix {
+			mounts = append(mounts, m)
+		}
+	}
+	mounts = append(mounts, contentMount{prefix: prefix, r: sub})
+	sort.SliceStable(mounts, func(i, j int) bool {
+		return len(mounts[i].prefix) > len(mounts[j].prefix)
+	})
+	r.mounts = mounts
+	return nil
+}
+
+// Is x this registry or mounted (directly or indirectly) in it?
+func (r *ContentRegistry) reaches(x *ContentRegistry) bool {
+	if r == x {
+		return true
+	}
+	r.mutex.RLock()
+	mounts := r.mounts
+	r.mutex.RUnlock()
+	for _, m := range mounts {
+		if m.r.reaches(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add content for each file under directory dir of fsys (e.g. an embed.FS) at URL path prefix
+// followed by file path relative to dir.  Content types are given by TypeByExtension.
+func (r *ContentRegistry) AddFS(prefix string, fsys fs.FS, dir string) error {
+	prefix = cleanPath(prefix)
+	return fs.WalkDir(fsys, dir, func(p string, de fs.DirEntry, err error) error {
+		if err != nil || de.IsDir() {
+			return err
+		}
+		rel := p
+		if dir != "." {
+			rel = strings.TrimPrefix(p, dir+"/")
+		}
+		c := &Content{
+			URLPath:  path.Join(prefix, rel),
+			FS:       fsys,
+			FilePath: p,
+		}
+		c.ContentType, _ = TypeByExtension(path.Ext(p))
+		if fi, err := de.Info(); err == nil && !fi.ModTime().IsZero() {
+			c.UnixTimeLastModified = fi.ModTime().Unix()
+		}
+		return r.Add(c)
+	})
+}
+
+// Add directory of fsys to default registry.  Panics on error (e.g. duplicate path).
+//
+//	//go:embed static
+//	var static embed.FS
+//	func init() { weeb.RegisterFS("/static/", static, "static") }
+func RegisterFS(prefix string, fsys fs.FS, dir string) {
+	if err := DefaultContentRegistry.AddFS(prefix, fsys, dir); err != nil {
+		panic(err)
+	}
+}
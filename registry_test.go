@@ -0,0 +1,198 @@
+package weeb
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/platinasystems/weeb/html"
+)
+
+func TestContentRegistry(t *testing.T) {
+	var r, sub, subsub ContentRegistry
+	a := &Content{URLPath: "/a.js"}
+	r.MustAdd(a)
+	if err := r.Add(&Content{URLPath: "/a.js"}); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("duplicate add: got %v", err)
+	}
+	b := &Content{URLPath: "/b.css"}
+	sub.MustAdd(b)
+	sub.MustAdd(&Content{URLPath: "/a.js"})
+	c := &Content{URLPath: "/c.png"}
+	subsub.MustAdd(c)
+	if err := r.Mount("/static/", &sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Mount("img", &subsub); err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []struct {
+		path string
+		want *Content
+	}{
+		{"/a.js", a},
+		{"/static/b.css", b},
+		{"/static/img/c.png", c},
+		{"/b.css", nil},
+		{"/staticb.css", nil},
+		{"/static/c.png", nil},
+	} {
+		if got, ok := r.Lookup(x.path); got != x.want || ok != (x.want != nil) {
+			t.Errorf("%s: got %v %v want %v", x.path, got, ok, x.want)
+		}
+	}
+	want := []string{"/a.js", "/static/a.js", "/static/b.css", "/static/img/c.png"}
+	if got := r.Paths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got paths %v want %v", got, want)
+	}
+
+	// Mounting at same prefix replaces.
+	if err := r.Mount("/static", &subsub); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := r.Lookup("/static/c.png"); got != c {
+		t.Errorf("replaced mount: got %v", got)
+	}
+	if !r.Remove("/a.js") || r.Remove("/a.js") {
+		t.Error("remove")
+	}
+	if _, ok := r.Lookup("/a.js"); ok {
+		t.Error("removed content found")
+	}
+}
+
+func TestContentRegistryMountCycle(t *testing.T) {
+	var a, b, c ContentRegistry
+	if err := a.Mount("/b", &b); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Mount("/c", &c); err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []struct {
+		r, sub *ContentRegistry
+	}{
+		{&a, &a},
+		{&b, &a},
+		{&c, &a},
+		{&c, &b},
+	} {
+		if err := x.r.Mount("/x", x.sub); err == nil {
+			t.Errorf("%p in %p: expected cycle error", x.sub, x.r)
+		}
+	}
+	if _, ok := a.Lookup("/x/y"); ok {
+		t.Error("found content in empty registries")
+	}
+}
+
+// Lookups concurrent with mounts and adds; run with -race.
+func TestContentRegistryConcurrent(t *testing.T) {
+	var r ContentRegistry
+	subs := make([]ContentRegistry, 8)
+	var wg sync.WaitGroup
+	for i := range subs {
+		wg.Add(2)
+		go func(sub *ContentRegistry) {
+			defer wg.Done()
+			sub.MustAdd(&Content{URLPath: "/x"})
+			r.Mount("/s", sub)
+		}(&subs[i])
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Lookup("/s/x")
+				r.Paths()
+			}
+		}()
+	}
+	wg.Wait()
+	if _, ok := r.Lookup("/s/x"); !ok {
+		t.Error("/s/x not found")
+	}
+}
+
+func TestAddFS(t *testing.T) {
+	mtime := time.Unix(1500000000, 0)
+	fsys := fstest.MapFS{
+		"static/a.js":         {Data: []byte("var a;"), ModTime: mtime},
+		"static/css/b.css":    {Data: []byte("b {}")},
+		"static/img/c.PNG":    {Data: []byte("\x89PNG")},
+		"static/notes.xyz":    {Data: []byte("x")},
+		"other/d.js":          {Data: []byte("var d;")},
+		"static/empty/.keep":  {},
+		"static/nested/e.txt": {Data: []byte("e")},
+	}
+	var r ContentRegistry
+	if err := r.AddFS("/s/", fsys, "static"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/s/a.js", "/s/css/b.css", "/s/empty/.keep", "/s/img/c.PNG", "/s/nested/e.txt", "/s/notes.xyz"}
+	if got := r.Paths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got paths %v want %v", got, want)
+	}
+	for _, c := range []struct {
+		path, contentType, body string
+	}{
+		{"/s/a.js", "text/javascript", "var a;"},
+		{"/s/css/b.css", "text/css", "b {}"},
+		{"/s/img/c.PNG", "image/png", "\x89PNG"},
+		{"/s/nested/e.txt", "text/plain; charset=utf-8", "e"},
+	} {
+		w := serve(&r, c.path)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != c.contentType || w.Body.String() != c.body {
+			t.Errorf("%s: got %d %q %q", c.path, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+	if c, _ := r.Lookup("/s/a.js"); c.UnixTimeLastModified != mtime.Unix() {
+		t.Errorf("got modification time %d want %d", c.UnixTimeLastModified, mtime.Unix())
+	}
+	// Adding again fails with duplicates.
+	if err := r.AddFS("/s", fsys, "static"); err == nil {
+		t.Error("expected duplicate error")
+	}
+	var all ContentRegistry
+	if err := all.AddFS("/", fsys, "."); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := all.Lookup("/other/d.js"); !ok {
+		t.Error("/other/d.js not found")
+	}
+}
+
+// Manifest assets resolve names of content not in registry (e.g. in gopherjs clients).
+func TestResolveAsset(t *testing.T) {
+	var r, sub ContentRegistry
+	r.MustAdd(&Content{URLPath: "/js/a.1234.js", Name: "/js/a.js"})
+	r.MustAdd(&Content{URLPath: "/js/plain.js"})
+	r.MustAdd(&Content{URLPath: "/js/i.js", Integrity: "sha256-abc"})
+	r.AddAssets(html.AssetMap{
+		"/js/a.js": {URL: "/js/a.old.js"},
+		"/js/b.js": {URL: "/js/b.5678.js", Integrity: "sha256-def"},
+	})
+	sub.AddAssets(html.AssetMap{"/c.css": {URL: "/c.9abc.css", Integrity: "sha384-ghi"}})
+	if err := r.Mount("/static", &sub); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name string
+		want html.Asset
+		ok   bool
+	}{
+		{"/js/a.js", html.Asset{URL: "/js/a.1234.js"}, true},
+		{"/js/a.1234.js", html.Asset{URL: "/js/a.1234.js"}, true},
+		{"/js/plain.js", html.Asset{URL: "/js/plain.js"}, true},
+		{"/js/i.js", html.Asset{URL: "/js/i.js", Integrity: "sha256-abc"}, true},
+		{"/js/b.js", html.Asset{URL: "/js/b.5678.js", Integrity: "sha256-def"}, true},
+		{"/static/c.css", html.Asset{URL: "/static/c.9abc.css", Integrity: "sha384-ghi"}, true},
+		{"/js/x.js", html.Asset{}, false},
+	} {
+		if a, ok := r.ResolveAsset(c.name); a != c.want || ok != c.ok {
+			t.Errorf("%s: got %+v %v want %+v %v", c.name, a, ok, c.want, c.ok)
+		}
+	}
+}
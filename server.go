@@ -0,0 +1,397 @@
+// +build !js
+
+package weeb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/platinasystems/weeb/html"
+	"golang.org/x/net/websocket"
+)
+
+// Serve registered content, websocket RPC and pages matching request path; otherwise 404.
+//
+//	http.ListenAndServe(":8080", site)
+func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.RpcReceivers != nil && s.isRpcPath(r.URL.Path) {
+		websocket.Handler(s.serveRpc).ServeHTTP(w, r)
+		return
+	}
+	if c, ok := s.content().Lookup(r.URL.Path); ok {
+		c.ServeHTTP(w, r)
+		return
+	}
+	s.servePage(w, r)
+}
+
+// Serve registered content; otherwise 404.
+func (r *ContentRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if c, ok := r.Lookup(req.URL.Path); ok {
+		c.ServeHTTP(w, req)
+	} else {
+		http.NotFound(w, req)
+	}
+}
+
+func (s *Site) rpcPath() string {
+	if len(s.RpcPath) > 0 {
+		return s.RpcPath
+	}
+	return DefaultRpcPath
+}
+
+// Is path the websocket RPC endpoint?  RPC path ending in slash matches any path with it as prefix.
+func (s *Site) isRpcPath(path string) bool {
+	p := s.rpcPath()
+	if n := len(p); n > 1 && p[n-1] == '/' {
+		return strings.HasPrefix(path, p)
+	}
+	return path == p
+}
+
+func (s *Site) serveRpc(ws *websocket.Conn) {
+	// Gob based RPC requires binary web socket frames.
+	ws.PayloadType = websocket.BinaryFrame
+	r := &Rpc{}
+	r.Init(ws, s.RpcReceivers(r)...)
+	if err := r.Serve(); err != nil && err != io.EOF {
+		log.Printf("rpc: %v", err)
+	}
+	ws.Close()
+}
+
+// Optional interface for pages which set response status or headers, redirect or fail.
+// PageResponse is called before the page is rendered; returned status 0 means http.StatusOK.
+// Returning a non-nil error (e.g. *StatusError or *Redirect) replaces the page.
+type ResponsePage interface {
+	Page
+	PageResponse(w http.ResponseWriter, r *http.Request, params Params) (status int, err error)
+}
+
+// Error with HTTP status code (e.g. http.StatusForbidden) rendered by site error page.
+type StatusError struct {
+	Code int
+	// Optional error shown on error page.
+	Err error
+}
+
+func (e *StatusError) Error() string {
+	s := strconv.Itoa(e.Code) + " " + http.StatusText(e.Code)
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+func (e *StatusError) Unwrap() error { return e.Err }
+
+// Redirect client to URL.  Code defaults to http.StatusFound.
+type Redirect struct {
+	Code int
+	URL  string
+}
+
+func (e *Redirect) Error() string { return "redirect to " + e.URL }
+
+func (s *Site) servePage(w http.ResponseWriter, r *http.Request) {
+	p, params, _ := s.MatchParams(r.URL.Path)
+	if p == nil {
+		s.serveError(w, r, &StatusError{Code: http.StatusNotFound})
+		return
+	}
+	status := http.StatusOK
+	if rp, ok := p.(ResponsePage); ok {
+		code, err := rp.PageResponse(w, r, params)
+		if err != nil {
+			s.serveError(w, r, err)
+			return
+		}
+		if code != 0 {
+			status = code
+		}
+	}
+	s.writeDoc(w, r, status, s.NewDoc(p, r.URL.Path, params))
+}
+
+func (s *Site) writeDoc(w http.ResponseWriter, r *http.Request, status int, d *html.Doc) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if r.Method != "HEAD" {
+		d.WriteTo(w)
+	}
+}
+
+// Redirect or render site error page.  Errors other than *StatusError are logged and
+// reported as http.StatusInternalServerError.
+func (s *Site) serveError(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		re *Redirect
+		se *StatusError
+	)
+	status := http.StatusInternalServerError
+	switch {
+	case errors.As(err, &re):
+		code := re.Code
+		if code == 0 {
+			code = http.StatusFound
+		}
+		http.Redirect(w, r, re.URL, code)
+		return
+	case errors.As(err, &se):
+		status = se.Code
+	default:
+		log.Printf("%s: %v", r.URL.Path, err)
+	}
+	ep := s.ErrorPage
+	if ep == nil {
+		ep = defaultErrorPage{}
+	}
+	d := &html.Doc{Assets: s.content()}
+	d.Head = s.head([]html.HeadNode{&html.Title{X: strconv.Itoa(status) + " " + http.StatusText(status)}})
+	d.Body = ep.ErrorBody(status, err, d)
+	s.writeDoc(w, r, status, d)
+}
+
+type defaultErrorPage struct{}
+
+// Status with message only for status errors so that internal errors are not shown to clients.
+func (defaultErrorPage) ErrorBody(status int, err error, d *html.Doc) html.BodyVec {
+	x := html.BodyVec{d.H1(&html.String{X: strconv.Itoa(status) + " " + http.StatusText(status)})}
+	var se *StatusError
+	if errors.As(err, &se) && se.Err != nil {
+		x = append(x, d.P(&html.String{X: se.Err.Error()}))
+	}
+	return x
+}
+
+// Open content inline data or file (in FS if set).  Caller must close returned file if non-nil.
+func (c *Content) open() (rs io.ReadSeeker, f io.Closer, fileMod time.Time, err error) {
+	if len(c.Data) > 0 {
+		rs = bytes.NewReader(c.Data)
+		return
+	}
+	var file fs.File
+	if c.FS != nil {
+		file, err = c.FS.Open(c.FilePath)
+	} else {
+		file, err = os.Open(c.FilePath)
+	}
+	if err != nil {
+		return
+	}
+	f = file
+	if fi, err := file.Stat(); err == nil {
+		fileMod = fi.ModTime()
+	}
+	if s, ok := file.(io.ReadSeeker); ok {
+		rs = s
+		return
+	}
+	// File systems need not support seeking (required for ranges and content sniffing).
+	var b []byte
+	if b, err = ioutil.ReadAll(file); err == nil {
+		rs = bytes.NewReader(b)
+	}
+	return
+}
+
+// Serve content inline data or file contents (404 if file cannot be opened).
+// Encoding is negotiated with client: alternates are offered to clients which accept them and gzip
+// content is decoded for clients which do not accept gzip (or request a range).
+// Conditional requests are handled using entity tag and modification time of served encoding.
+func (c *Content) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	if len(c.ContentEncoding) != 0 || len(c.Alternates) != 0 {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	var (
+		rs   io.ReadSeeker
+		etag string
+		err  error
+	)
+	x, decode := c.negotiate(r)
+	if x == nil {
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return
+	}
+	if decode {
+		rs, etag, err = c.decode()
+	} else {
+		var f io.Closer
+		var fileMod time.Time
+		rs, f, fileMod, err = x.open()
+		if f != nil {
+			defer f.Close()
+		}
+		if err == nil {
+			etag, err = x.entityTag(rs, fileMod)
+		}
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("%s: %v", c.URLPath, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if len(c.ContentType) != 0 {
+		h.Set("Content-Type", c.ContentType)
+	}
+	if !decode && len(x.ContentEncoding) != 0 {
+		h.Set("Content-Encoding", x.ContentEncoding)
+	}
+	h.Set("Etag", etag)
+	if len(c.CacheControl) != 0 {
+		h.Set("Cache-Control", c.CacheControl)
+	} else {
+		h.Set("Cache-Control", CacheRevalidate)
+	}
+	http.ServeContent(w, r, "", time.Unix(c.UnixTimeLastModified, 0), rs)
+}
+
+// Choose encoding acceptable to client preferring alternates then content itself.
+// Decode is true when gzip content should be served decoded.  X is nil when client sent
+// Accept-Encoding excluding all encodings of content (which cannot be decoded).
+func (c *Content) negotiate(r *http.Request) (x *Content, decode bool) {
+	x = c
+	canDecode := c.ContentEncoding == "gzip"
+	// Ranges of compressed bytes are of no use to clients.
+	if canDecode && len(r.Header.Get("Range")) != 0 {
+		decode = true
+		return
+	}
+	ae := parseAcceptEncoding(r.Header.Get("Accept-Encoding"))
+	q := ae.quality(c.ContentEncoding)
+	// Highest quality alternate; earlier alternates win ties.
+	var (
+		best *Content
+		qb   float64
+	)
+	for _, a := range c.Alternates {
+		if qa := ae.quality(a.ContentEncoding); qa > qb {
+			best, qb = a, qa
+		}
+	}
+	if best != nil && qb >= q {
+		x, q = best, qb
+	}
+	// Identity is implicitly acceptable but only preferred to encodings when listed.
+	_, listed := ae["identity"]
+	if canDecode && ae.quality("") > q && (q == 0 || listed) {
+		x, decode = c, true
+	}
+	// Clients sending no Accept-Encoding accept any encoding.
+	if _, sent := r.Header["Accept-Encoding"]; sent && q == 0 && !decode && len(c.ContentEncoding) != 0 {
+		x = nil
+	}
+	return
+}
+
+// Decoded gzip content and its entity tag.  Decoded content is kept until file changes.
+func (c *Content) decode() (rs io.ReadSeeker, etag string, err error) {
+	src, f, fileMod, err := c.open()
+	if f != nil {
+		defer f.Close()
+	}
+	if err != nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.decoded == nil || !c.decodedModTime.Equal(fileMod) {
+		var zr *gzip.Reader
+		if zr, err = gzip.NewReader(src); err != nil {
+			return
+		}
+		var b []byte
+		if b, err = ioutil.ReadAll(zr); err != nil {
+			return
+		}
+		c.decoded, c.decodedModTime = b, fileMod
+		c.decodedETag, _ = hashTag(bytes.NewReader(b))
+	}
+	return bytes.NewReader(c.decoded), c.decodedETag, nil
+}
+
+// Quality values by content coding from Accept-Encoding header.
+type acceptEncoding map[string]float64
+
+func parseAcceptEncoding(h string) (ae acceptEncoding) {
+	ae = make(acceptEncoding)
+	for _, x := range strings.Split(h, ",") {
+		x = strings.TrimSpace(x)
+		q := 1.0
+		if i := strings.IndexByte(x, ';'); i >= 0 {
+			p := strings.TrimSpace(x[i+1:])
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+			x = strings.TrimSpace(x[:i])
+		}
+		if len(x) > 0 {
+			ae[strings.ToLower(x)] = q
+		}
+	}
+	return
+}
+
+// Quality of coding (empty for identity).  Identity is acceptable unless explicitly excluded.
+func (ae acceptEncoding) quality(coding string) float64 {
+	if len(coding) == 0 {
+		coding = "identity"
+	}
+	if q, ok := ae[coding]; ok {
+		return q
+	}
+	if q, ok := ae["*"]; ok {
+		return q
+	}
+	if coding == "identity" {
+		return 1
+	}
+	return 0
+}
+
+// Strong entity tag from SHA-256 hash of content as served (i.e. after any encoding).
+// Hash is computed once for inline data and again for files when modification time changes.
+func (c *Content) entityTag(rs io.ReadSeeker, fileMod time.Time) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.etag) > 0 && c.etagModTime.Equal(fileMod) {
+		return c.etag, nil
+	}
+	etag, err := hashTag(rs)
+	if err != nil {
+		return "", err
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	c.etag, c.etagModTime = etag, fileMod
+	return c.etag, nil
+}
+
+func hashTag(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`, nil
+}
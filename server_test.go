@@ -0,0 +1,384 @@
+// +build !js
+
+package weeb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platinasystems/weeb/html"
+)
+
+type testPage struct{ s string }
+
+func (p *testPage) PageBody(path string, d *html.Doc) html.BodyVec {
+	return html.BodyVec{d.P(p.s + " " + path)}
+}
+
+func serve(h http.Handler, path string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestServeHTTP(t *testing.T) {
+	var r ContentRegistry
+	r.MustAdd(&Content{URLPath: "/test/a.js", Data: []byte("var a;"), ContentType: "text/javascript"})
+	r.MustAdd(&Content{URLPath: "/test/missing.js", FilePath: "testdata/missing.js", ContentType: "text/javascript"})
+	s := &Site{
+		PageByPath: map[string]Page{"/": &testPage{"root"}, "/a/": &testPage{"a"}, "/exec": &testPage{"exec"}},
+		Head:       []html.HeadNode{&html.Title{X: "T"}},
+		Content:    &r,
+	}
+	for _, c := range []struct {
+		method, path string
+		code         int
+		contentType  string
+		body         string
+	}{
+		{"GET", "/", http.StatusOK, "text/html; charset=utf-8",
+			`<!DOCTYPE html><html><head><title>T</title></head><body><p>root /</p></body></html>`},
+		{"GET", "/a/b", http.StatusOK, "text/html; charset=utf-8",
+			`<!DOCTYPE html><html><head><title>T</title></head><body><p>a /a/b</p></body></html>`},
+		{"HEAD", "/exec", http.StatusOK, "text/html; charset=utf-8", ""},
+		{"GET", "/test/a.js", http.StatusOK, "text/javascript", "var a;"},
+		{"GET", "/test/missing.js", http.StatusNotFound, "text/plain; charset=utf-8", "404 page not found\n"},
+		// RPC endpoint is disabled without receivers.
+		{"GET", DefaultRpcPath, http.StatusOK, "text/html; charset=utf-8",
+			`<!DOCTYPE html><html><head><title>T</title></head><body><p>root /ws/rpc/</p></body></html>`},
+	} {
+		r := httptest.NewRequest(c.method, c.path, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != c.code || w.Header().Get("Content-Type") != c.contentType || w.Body.String() != c.body {
+			t.Errorf("%s %s: got %d %q %q want %d %q %q", c.method, c.path,
+				w.Code, w.Header().Get("Content-Type"), w.Body.String(), c.code, c.contentType, c.body)
+		}
+	}
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// Pages and content (both gzip and decoded) served concurrently; run with -race.
+func TestServeConcurrent(t *testing.T) {
+	var r ContentRegistry
+	r.MustAdd(&Content{URLPath: "/js/a.js", Data: []byte("var a;"), ContentType: "text/javascript"})
+	r.MustAdd(&Content{URLPath: "/js/z.js", Data: gzipped(t, "var z;"), ContentType: "text/javascript", ContentEncoding: "gzip"})
+	s := &Site{
+		PageByPath: map[string]Page{"/": &testPage{"root"}, "/a/": &testPage{"a"}},
+		Head:       []html.HeadNode{&html.Title{X: "T"}, &html.Script{Src: "/js/a.js"}},
+		Content:    &r,
+	}
+	for _, c := range []struct {
+		path, acceptEncoding string
+		want                 string
+	}{
+		{"/", "", "root /"},
+		{"/a/b", "", "a /a/b"},
+		{"/js/a.js", "gzip", "var a;"},
+		{"/js/z.js", "", "var z;"},
+		{"/js/z.js", "gzip", "var z;"},
+	} {
+		c := c
+		t.Run(c.path+" "+c.acceptEncoding, func(t *testing.T) {
+			t.Parallel()
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 50; j++ {
+						w := serve(s, c.path, "Accept-Encoding", c.acceptEncoding)
+						body := w.Body.Bytes()
+						if w.Header().Get("Content-Encoding") == "gzip" {
+							zr, err := gzip.NewReader(w.Body)
+							if err != nil {
+								t.Error(err)
+								return
+							}
+							body, _ = ioutil.ReadAll(zr)
+						}
+						if w.Code != http.StatusOK || !strings.Contains(string(body), c.want) {
+							t.Errorf("%s: %d %q", c.path, w.Code, body)
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+type responsePage struct {
+	testPage
+	status int
+	err    error
+}
+
+func (p *responsePage) PageHead(path string, d *html.Doc) []html.HeadNode {
+	return []html.HeadNode{&html.Title{X: "P"}, &html.Meta{Name: "description", Content: path}}
+}
+
+func (p *responsePage) PageResponse(w http.ResponseWriter, r *http.Request, params Params) (int, error) {
+	w.Header().Set("X-Page", params["x"])
+	return p.status, p.err
+}
+
+type testErrorPage struct{}
+
+func (testErrorPage) ErrorBody(status int, err error, d *html.Doc) html.BodyVec {
+	return html.BodyVec{d.P(&html.String{X: http.StatusText(status)})}
+}
+
+func TestPageResponse(t *testing.T) {
+	s := &Site{
+		PageByPath: map[string]Page{
+			"/ok/{x}":   &responsePage{testPage: testPage{"ok"}},
+			"/new":      &responsePage{testPage: testPage{"new"}, status: http.StatusCreated},
+			"/old":      &responsePage{err: &Redirect{URL: "/new"}},
+			"/moved":    &responsePage{err: &Redirect{Code: http.StatusMovedPermanently, URL: "/new"}},
+			"/denied":   &responsePage{err: &StatusError{Code: http.StatusForbidden, Err: errors.New("no <access>")}},
+			"/wrapped":  &responsePage{err: fmt.Errorf("wrap: %w", &StatusError{Code: http.StatusGone})},
+			"/internal": &responsePage{err: errors.New("secret")},
+		},
+		Head: []html.HeadNode{&html.Title{X: "T"}, &html.Meta{Charset: "utf-8"}},
+	}
+	const head = `<!DOCTYPE html><html><head><meta charset="utf-8"/>`
+	for _, c := range []struct {
+		path      string
+		code      int
+		header    string
+		body      string
+		errorPage bool
+	}{
+		{"/ok/a", http.StatusOK, "a",
+			head + `<title>P</title><meta name="description" content="/ok/a"/></head><body><p>ok /ok/a</p></body></html>`, false},
+		{"/new", http.StatusCreated, "",
+			head + `<title>P</title><meta name="description" content="/new"/></head><body><p>new /new</p></body></html>`, false},
+		{"/old", http.StatusFound, "/new", "", false},
+		{"/moved", http.StatusMovedPermanently, "/new", "", false},
+		{"/denied", http.StatusForbidden, "",
+			head + `<title>403 Forbidden</title></head><body><h1>403 Forbidden</h1><p>no &lt;access&gt;</p></body></html>`, false},
+		{"/wrapped", http.StatusGone, "",
+			head + `<title>410 Gone</title></head><body><h1>410 Gone</h1></body></html>`, false},
+		// Internal errors are not shown to clients.
+		{"/internal", http.StatusInternalServerError, "",
+			head + `<title>500 Internal Server Error</title></head><body><h1>500 Internal Server Error</h1></body></html>`, false},
+		{"/none", http.StatusNotFound, "",
+			head + `<title>404 Not Found</title></head><body><h1>404 Not Found</h1></body></html>`, false},
+		{"/none", http.StatusNotFound, "",
+			head + `<title>404 Not Found</title></head><body><p>Not Found</p></body></html>`, true},
+	} {
+		s.ErrorPage = nil
+		if c.errorPage {
+			s.ErrorPage = testErrorPage{}
+		}
+		w := serve(s, c.path)
+		header := w.Header().Get("X-Page")
+		if c.code/100 == 3 {
+			header = w.Header().Get("Location")
+		}
+		body := w.Body.String()
+		if c.code/100 == 3 {
+			body = ""
+		}
+		if w.Code != c.code || header != c.header || body != c.body {
+			t.Errorf("%s: got %d %q %s want %d %q %s", c.path, w.Code, header, body, c.code, c.header, c.body)
+		}
+	}
+}
+
+func TestContentETag(t *testing.T) {
+	c := &Content{URLPath: "/a.js", Data: []byte("var a = 1;"), ContentType: "text/javascript"}
+	etag := serve(c, "/a.js").Header().Get("Etag")
+	if len(etag) != 34 || etag[0] != '"' || etag[33] != '"' {
+		t.Fatalf("bad etag %s", etag)
+	}
+	for _, c := range []struct {
+		c            *Content
+		header       []string
+		code         int
+		cacheControl string
+		body         string
+	}{
+		{c, nil, http.StatusOK, CacheRevalidate, "var a = 1;"},
+		{c, []string{"If-None-Match", etag}, http.StatusNotModified, CacheRevalidate, ""},
+		{c, []string{"If-None-Match", `"x", ` + etag}, http.StatusNotModified, CacheRevalidate, ""},
+		{c, []string{"If-None-Match", `"x"`}, http.StatusOK, CacheRevalidate, "var a = 1;"},
+		{c, []string{"Range", "bytes=4-4"}, http.StatusPartialContent, CacheRevalidate, "a"},
+		{c, []string{"Range", "bytes=0-2", "If-Range", etag}, http.StatusPartialContent, CacheRevalidate, "var"},
+		{c, []string{"Range", "bytes=0-2", "If-Range", `"x"`}, http.StatusOK, CacheRevalidate, "var a = 1;"},
+		{&Content{Data: []byte("x"), CacheControl: CacheImmutable}, nil, http.StatusOK, CacheImmutable, "x"},
+	} {
+		w := serve(c.c, "/a.js", c.header...)
+		if w.Code != c.code || w.Header().Get("Cache-Control") != c.cacheControl || w.Body.String() != c.body {
+			t.Errorf("%v: got %d %q %q want %d %q %q", c.header,
+				w.Code, w.Header().Get("Cache-Control"), w.Body.String(), c.code, c.cacheControl, c.body)
+		}
+		if w.Code != http.StatusOK && w.Code != http.StatusPartialContent && w.Code != http.StatusNotModified {
+			continue
+		}
+		if c.c.CacheControl == "" && w.Header().Get("Etag") != etag {
+			t.Errorf("%v: got etag %s want %s", c.header, w.Header().Get("Etag"), etag)
+		}
+	}
+}
+
+// File entity tag changes when file is modified.
+func TestContentFileETag(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "a.txt")
+	if err := ioutil.WriteFile(fn, []byte("one"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := &Content{URLPath: "/a.txt", FilePath: fn}
+	w := serve(c, "/a.txt")
+	etag := w.Header().Get("Etag")
+	if w.Code != http.StatusOK || w.Body.String() != "one" || etag == "" {
+		t.Fatalf("got %d %q %s", w.Code, w.Body.String(), etag)
+	}
+	if w := serve(c, "/a.txt", "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("got %d want 304", w.Code)
+	}
+	if err := ioutil.WriteFile(fn, []byte("two"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(fn, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	w = serve(c, "/a.txt", "If-None-Match", etag)
+	if w.Code != http.StatusOK || w.Body.String() != "two" || w.Header().Get("Etag") == etag {
+		t.Errorf("got %d %q %s after modification", w.Code, w.Body.String(), w.Header().Get("Etag"))
+	}
+}
+
+func TestParseAcceptEncoding(t *testing.T) {
+	for _, c := range []struct {
+		h    string
+		want acceptEncoding
+	}{
+		{"", acceptEncoding{}},
+		{"gzip", acceptEncoding{"gzip": 1}},
+		{"gzip, deflate, br", acceptEncoding{"gzip": 1, "deflate": 1, "br": 1}},
+		{"br;q=0.5, GZIP ;q=0.8", acceptEncoding{"br": 0.5, "gzip": 0.8}},
+		{"identity;q=0, *;q=0", acceptEncoding{"identity": 0, "*": 0}},
+		{"gzip;q=x, ,br; level=1", acceptEncoding{"gzip": 1, "br": 1}},
+	} {
+		if got := parseAcceptEncoding(c.h); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: got %v want %v", c.h, got, c.want)
+		}
+	}
+}
+
+func TestAcceptEncodingQuality(t *testing.T) {
+	for _, c := range []struct {
+		h, coding string
+		want      float64
+	}{
+		{"", "", 1},
+		{"", "gzip", 0},
+		{"gzip", "", 1},
+		{"gzip;q=0.5", "gzip", 0.5},
+		{"*", "br", 1},
+		{"*;q=0", "", 0},
+		{"identity;q=0.2, *;q=0", "", 0.2},
+		{"gzip, *;q=0.1", "br", 0.1},
+	} {
+		if got := parseAcceptEncoding(c.h).quality(c.coding); got != c.want {
+			t.Errorf("%q %q: got %v want %v", c.h, c.coding, got, c.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	br := &Content{ContentEncoding: "br"}
+	zstd := &Content{ContentEncoding: "zstd"}
+	gz := &Content{ContentEncoding: "gzip", Alternates: []*Content{br, zstd}}
+	plain := &Content{}
+	brOnly := &Content{ContentEncoding: "br"}
+	for _, c := range []struct {
+		name           string
+		c              *Content
+		acceptEncoding string
+		rangeHeader    string
+		want           *Content
+		decode         bool
+	}{
+		{"plain", plain, "gzip", "", plain, false},
+		{"gzip only", gz, "gzip", "", gz, false},
+		{"alternate preferred", gz, "gzip, br", "", br, false},
+		{"alternate lower quality", gz, "gzip, br;q=0.5", "", gz, false},
+		{"alternate only", gz, "br", "", br, false},
+		{"highest quality alternate", gz, "br;q=0.5, zstd;q=0.8", "", zstd, false},
+		{"highest quality alternate over content", gz, "gzip;q=0.6, br;q=0.5, zstd;q=0.8", "", zstd, false},
+		{"content over alternates", gz, "gzip, br;q=0.5, zstd;q=0.8", "", gz, false},
+		{"no encodings decodes", gz, "", "", gz, true},
+		{"lower quality gzip", gz, "gzip;q=0.5", "", gz, false},
+		{"identity preferred decodes", gz, "gzip;q=0.5, identity", "", gz, true},
+		{"identity excluded", gz, "gzip, identity;q=0", "", gz, false},
+		{"wildcard", gz, "*", "", br, false},
+		{"range decodes", gz, "gzip, br", "bytes=0-", gz, true},
+		{"range of plain", plain, "", "bytes=0-", plain, false},
+		// Content which cannot be decoded is not acceptable to clients excluding its encoding.
+		{"encoding not accepted", brOnly, "gzip", "", nil, false},
+		{"encoding excluded", brOnly, "br;q=0", "", nil, false},
+		{"encoding accepted", brOnly, "gzip, br", "", brOnly, false},
+		{"no accept-encoding", brOnly, "", "", brOnly, false},
+		{"gzip with identity excluded", gz, "deflate, identity;q=0", "", nil, false},
+		{"plain with identity excluded", plain, "identity;q=0", "", plain, false},
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		if len(c.acceptEncoding) != 0 {
+			r.Header.Set("Accept-Encoding", c.acceptEncoding)
+		}
+		if len(c.rangeHeader) != 0 {
+			r.Header.Set("Range", c.rangeHeader)
+		}
+		x, decode := c.c.negotiate(r)
+		if x != c.want || decode != c.decode {
+			t.Errorf("%s: got %+v decode %v want %+v decode %v", c.name, x, decode, c.want, c.decode)
+		}
+	}
+	c := &Content{URLPath: "/a.js", Data: []byte("a"), ContentEncoding: "br"}
+	if w := serve(c, "/a.js", "Accept-Encoding", "gzip"); w.Code != http.StatusNotAcceptable {
+		t.Errorf("got %d want 406", w.Code)
+	}
+}
+
+// Missing files are not found whether served as is or decoded.
+func TestContentMissingFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "a.js.gz")
+	c := &Content{URLPath: "/a.js", FilePath: fn, ContentEncoding: "gzip"}
+	for _, acceptEncoding := range []string{"gzip", ""} {
+		if w := serve(c, "/a.js", "Accept-Encoding", acceptEncoding); w.Code != http.StatusNotFound {
+			t.Errorf("%q: got %d want 404", acceptEncoding, w.Code)
+		}
+	}
+}
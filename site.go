@@ -1,28 +1,114 @@
 package weeb
 
 import (
+	"io/fs"
 	"path"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/platinasystems/weeb/html"
 )
 
 type Site struct {
-	DocByPath  map[string]*html.Doc
+	// Documents rendered once for each page for use by client (gopherjs) code.
+	// ServeHTTP renders a new document for each request and does not use these.
+	DocByPath map[string]*html.Doc
+	// Pages by pattern (see MatchParams).  Call InitPatterns after setting up or changing.
 	PageByPath map[string]Page
+
+	// Head nodes (title, scripts, style sheets) for pages rendered by ServeHTTP.
+	Head []html.HeadNode
+
+	// URL path prefix of websocket RPC endpoint.  Default is DefaultRpcPath.
+	RpcPath string
+
+	// Receivers to register with RPC server for each new websocket connection.
+	// RPC endpoint is disabled if nil.
+	RpcReceivers func(r *Rpc) []interface{}
+
+	// Renders error pages (e.g. 404 Not Found).  Default shows status and error message.
+	ErrorPage ErrorPage
+
+	// Content served by site.  Default is DefaultContentRegistry.
+	Content *ContentRegistry
+
+	// Protects page pattern table.
+	mutex sync.Mutex
+	// Page patterns in order of precedence; nil until InitPatterns or first match.
+	routes []route
 }
 
+const DefaultRpcPath = "/ws/rpc/"
+
+// PageBody may be called concurrently for different requests each with its own document.
+// Implementations must render into d and not modify shared page state.
 type Page interface {
 	PageBody(path string, d *html.Doc) html.BodyVec
 }
 
-// Does path match pattern?
-func pathMatch(pattern, path string) bool {
-	n := len(pattern)
-	if n > 1 && pattern[n-1] == '/' {
-		return len(path) >= n && path[0:n] == pattern
+// Optional interface for pages contributing head nodes (e.g. title, meta tags, scripts).
+// Nodes are added to site head nodes; a page title replaces the site title.
+type HeadPage interface {
+	Page
+	PageHead(path string, d *html.Doc) []html.HeadNode
+}
+
+// Renders body of error page for HTTP status code and error (which may be nil).
+type ErrorPage interface {
+	ErrorBody(status int, err error, d *html.Doc) html.BodyVec
+}
+
+// Site head nodes followed by given nodes.  Site title is dropped if nodes have a title.
+func (s *Site) head(ns []html.HeadNode) []html.HeadNode {
+	if len(ns) == 0 {
+		// Limit capacity so that appends to result copy rather than share site head nodes.
+		return s.Head[:len(s.Head):len(s.Head)]
+	}
+	hasTitle := false
+	for _, n := range ns {
+		if _, ok := n.(*html.Title); ok {
+			hasTitle = true
+		}
+	}
+	var h []html.HeadNode
+	for _, n := range s.Head {
+		if _, ok := n.(*html.Title); !ok || !hasTitle {
+			h = append(h, n)
+		}
+	}
+	return append(h, ns...)
+}
+
+// New document with site head nodes and page body rendered for given path and parameters.
+func (s *Site) NewDoc(p Page, path string, params Params) (d *html.Doc) {
+	d = &html.Doc{Assets: s.content()}
+	var ns []html.HeadNode
+	if hp, ok := p.(HeadPage); ok {
+		ns = hp.PageHead(path, d)
+	}
+	d.Head = s.head(ns)
+	if pp, ok := p.(ParamsPage); ok {
+		d.Body = pp.PageBodyParams(path, params, d)
 	} else {
-		return pattern == path
+		d.Body = p.PageBody(path, d)
+	}
+	return
+}
+
+func (s *Site) content() *ContentRegistry {
+	if s.Content != nil {
+		return s.Content
+	}
+	return DefaultContentRegistry
+}
+
+// Render a document for each page into DocByPath.  Documents are keyed and
+// rendered by page pattern; use NewDoc for the document of a request path.
+func (s *Site) InitDocByPath() {
+	s.DocByPath = make(map[string]*html.Doc)
+	for path, p := range s.PageByPath {
+		s.DocByPath[path] = s.NewDoc(p, path, nil)
 	}
 }
 
@@ -44,21 +130,11 @@ func cleanPath(p string) string {
 	return np
 }
 
-// Find a handler on a handler map given a path string
-// Most-specific (longest) pattern wins
+// Find page and document for path.  See MatchParams for pattern syntax and precedence.
 func (s *Site) Match(key string) (p Page, d *html.Doc, pattern string) {
-	n := 0
-	key = cleanPath(key)
-	for path, page := range s.PageByPath {
-		if !pathMatch(path, key) {
-			continue
-		}
-		if p == nil || len(path) > n {
-			n = len(path)
-			p = page
-			pattern = path
-			d = s.DocByPath[path]
-		}
+	p, _, pattern = s.MatchParams(key)
+	if p != nil {
+		d = s.DocByPath[pattern]
 	}
 	return
 }
@@ -67,19 +143,53 @@ type Content struct {
 	// URL path used to index this content (url.Path)
 	URLPath string
 
+	// Logical name when URL path includes fingerprint (e.g. /js/foo.min.js for /js/foo.1a2b3c4d.min.js).
+	// Script and link nodes referring to name are resolved to URL path.
+	Name string
+
 	// Either FilePath is set or Data is provided inline.
+	// FilePath is opened in FS if set (e.g. an embed.FS); otherwise relative to working directory.
 	FilePath string
+	FS       fs.FS
 	Data     []byte
 
 	UnixTimeLastModified int64
 	ContentType          string
 	ContentEncoding      string
+
+	// Subresource integrity digest of content before encoding (e.g. sha384-BASE64).
+	// Script and link nodes referring to content are given integrity attributes.
+	Integrity string
+
+	// Cache-Control header value.  Default is CacheRevalidate.
+	CacheControl string
+
+	// Same content with alternate encodings (e.g. "br", "zstd") offered to clients which accept them.
+	// Each has ContentEncoding and either Data or FilePath set.
+	Alternates []*Content
+
+	// Protects entity tag and decoded content computed when first served.
+	mutex sync.Mutex
+	// Strong entity tag from hash of content.
+	etag string
+	// File modification time when etag was computed.
+	etagModTime time.Time
+	// Gzip content decoded for clients which do not accept gzip encoding.
+	decoded        []byte
+	decodedETag    string
+	decodedModTime time.Time
 }
 
-var ContentByPath = make(map[string]*Content)
+const (
+	// Clients may cache content but must revalidate (using ETag) before each use.
+	CacheRevalidate = "no-cache"
+	// Content never changes at its URL (e.g. URL includes hash of content).
+	CacheImmutable = "public, max-age=31536000, immutable"
+)
 
+// Add content to default registry.  Panics if URL path is already registered.
 func (c *Content) Register() {
-	ContentByPath[c.URLPath] = c
+	DefaultContentRegistry.MustAdd(c)
 }
 
 func GoPackageNameForPath(p string) (n string) {
@@ -0,0 +1,56 @@
+package weeb
+
+import "strings"
+
+type contentType struct {
+	name string
+	// Format is already compressed so gzip would only add overhead.
+	compressed bool
+}
+
+// Content types by file name extension.  A fixed table (rather than the system MIME database)
+// gives the same types on all hosts.
+var contentTypes = map[string]contentType{
+	".html":  {name: "text/html; charset=utf-8"},
+	".htm":   {name: "text/html; charset=utf-8"},
+	".css":   {name: "text/css"},
+	".js":    {name: "text/javascript"},
+	".mjs":   {name: "text/javascript"},
+	".json":  {name: "application/json"},
+	".map":   {name: "application/json"},
+	".xml":   {name: "application/xml"},
+	".txt":   {name: "text/plain; charset=utf-8"},
+	".csv":   {name: "text/csv; charset=utf-8"},
+	".md":    {name: "text/markdown; charset=utf-8"},
+	".svg":   {name: "image/svg+xml"},
+	".ico":   {name: "image/x-icon"},
+	".bmp":   {name: "image/bmp"},
+	".png":   {name: "image/png", compressed: true},
+	".jpg":   {name: "image/jpeg", compressed: true},
+	".jpeg":  {name: "image/jpeg", compressed: true},
+	".gif":   {name: "image/gif", compressed: true},
+	".webp":  {name: "image/webp", compressed: true},
+	".avif":  {name: "image/avif", compressed: true},
+	".ttf":   {name: "font/ttf"},
+	".otf":   {name: "font/otf"},
+	".eot":   {name: "application/vnd.ms-fontobject"},
+	".woff":  {name: "font/woff", compressed: true},
+	".woff2": {name: "font/woff2", compressed: true},
+	".wasm":  {name: "application/wasm"},
+	".pdf":   {name: "application/pdf"},
+	".mp3":   {name: "audio/mpeg", compressed: true},
+	".ogg":   {name: "audio/ogg", compressed: true},
+	".wav":   {name: "audio/wav"},
+	".mp4":   {name: "video/mp4", compressed: true},
+	".webm":  {name: "video/webm", compressed: true},
+	".zip":   {name: "application/zip", compressed: true},
+	".gz":    {name: "application/gzip", compressed: true},
+}
+
+// Content type for file name extension (e.g. ".css"; case is ignored) or empty if unknown.
+// Compressed is true for formats which gzip would not make smaller (e.g. images, fonts).
+// Used by both AddFS and weebgen so that served and generated content agree.
+func TypeByExtension(ext string) (t string, compressed bool) {
+	x := contentTypes[strings.ToLower(ext)]
+	return x.name, x.compressed
+}
@@ -0,0 +1,27 @@
+package weeb
+
+import "testing"
+
+func TestTypeByExtension(t *testing.T) {
+	for _, c := range []struct {
+		ext        string
+		want       string
+		compressed bool
+	}{
+		{".html", "text/html; charset=utf-8", false},
+		{".HTML", "text/html; charset=utf-8", false},
+		{".js", "text/javascript", false},
+		{".css", "text/css", false},
+		{".svg", "image/svg+xml", false},
+		{".png", "image/png", true},
+		{".woff2", "font/woff2", true},
+		{".wasm", "application/wasm", false},
+		{".gz", "application/gzip", true},
+		{".xyz", "", false},
+		{"", "", false},
+	} {
+		if got, compressed := TypeByExtension(c.ext); got != c.want || compressed != c.compressed {
+			t.Errorf("%q: got %q %v want %q %v", c.ext, got, compressed, c.want, c.compressed)
+		}
+	}
+}
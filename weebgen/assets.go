@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Input file to be registered as content.
+type asset struct {
+	inFile  string
+	urlPath string
+	// Go identifier derived from path used to name data files.
+	name string
+
+	data        []byte
+	contentType string
+	// Gzip compress data and generate alternate encodings.
+	compress bool
+}
+
+// Go identifier for path (e.g. css/eg.min.css => css_eg_min_css).
+func identifier(p string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, p)
+}
+
+// Expand arguments (files, directories or glob patterns) into assets with URL paths given by
+// prefix followed by path relative to directory argument (or for files, their directory).
+func (c *config) assets(args []string) (as []*asset, err error) {
+	add := func(file, base string) {
+		rel, e := filepath.Rel(base, file)
+		if e != nil {
+			rel = filepath.Base(file)
+		}
+		rel = filepath.ToSlash(rel)
+		as = append(as, &asset{
+			inFile:  file,
+			urlPath: path.Join(c.urlPrefix, rel),
+			name:    identifier(rel),
+		})
+	}
+	for _, arg := range args {
+		files := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			if files, err = filepath.Glob(arg); err != nil {
+				return
+			}
+		}
+		for _, f := range files {
+			var fi os.FileInfo
+			if fi, err = os.Stat(f); err != nil {
+				return
+			}
+			if !fi.IsDir() {
+				add(f, filepath.Dir(f))
+				continue
+			}
+			dir := f
+			err = filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+				switch {
+				case err != nil:
+				case fi.IsDir():
+					// Added or removed files change directory modification time.
+					c.dirs = append(c.dirs, p)
+				case !strings.HasPrefix(fi.Name(), ".") && !c.isOutput(p):
+					add(p, dir)
+				}
+				return err
+			})
+			if err != nil {
+				return
+			}
+		}
+		if strings.ContainsAny(arg, "*?[") {
+			c.dirs = append(c.dirs, filepath.Dir(arg))
+		}
+	}
+	as = c.withoutDataFiles(as)
+	sort.Slice(as, func(i, j int) bool { return as[i].urlPath < as[j].urlPath })
+	names := make(map[string]*asset)
+	for i, a := range as {
+		if i > 0 && as[i-1].urlPath == a.urlPath {
+			return nil, fmt.Errorf("%s and %s have same URL path %s", as[i-1].inFile, a.inFile, a.urlPath)
+		}
+		if x, ok := names[a.name]; ok {
+			return nil, fmt.Errorf("%s and %s have same data file name %s", x.inFile, a.inFile, a.name)
+		}
+		names[a.name] = a
+	}
+	return
+}
+
+// Is file generated by weebgen (i.e. output or manifest file, or output of another weebgen invocation)?
+func (c *config) isOutput(p string) bool {
+	switch filepath.Clean(p) {
+	case filepath.Clean(c.outFile), filepath.Clean(manifestFile(c.outFile)):
+		return true
+	}
+	return strings.HasSuffix(p, "_weebgen.go") || strings.HasSuffix(p, "_weebgen_manifest.go")
+}
+
+// Names of all data files weebgen may create for asset whatever the compression and encodings.
+func possibleDataFiles(a *asset) (fs []string) {
+	fs = append(fs, a.name, a.name+".gz")
+	for enc := range compressors {
+		fs = append(fs, a.name+"."+enc)
+	}
+	return
+}
+
+// Remove assets which are data files created in output directory for other assets.
+func (c *config) withoutDataFiles(as []*asset) (r []*asset) {
+	data := make(map[string][]*asset)
+	for _, a := range as {
+		for _, f := range possibleDataFiles(a) {
+			p := filepath.Join(c.outDir, f)
+			data[p] = append(data[p], a)
+		}
+	}
+	isData := func(a *asset) bool {
+		for _, x := range data[filepath.Clean(a.inFile)] {
+			if x != a {
+				return true
+			}
+		}
+		return false
+	}
+	for _, a := range as {
+		if !isData(a) {
+			r = append(r, a)
+		}
+	}
+	return
+}
+
+// Is output file newer than all inputs and generated with the same header (i.e. arguments)?
+func upToDate(outFile, header string, inputs []string) bool {
+	b, err := ioutil.ReadFile(outFile)
+	// Header ends at blank line so that output with further header lines does not match.
+	if err != nil || !strings.HasPrefix(string(b), header+"\n") {
+		return false
+	}
+	fi, err := os.Stat(outFile)
+	if err != nil {
+		return false
+	}
+	for _, in := range inputs {
+		if ii, err := os.Stat(in); err != nil || ii.ModTime().After(fi.ModTime()) {
+			return false
+		}
+	}
+	return true
+}
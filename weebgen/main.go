@@ -1,9 +1,12 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"go/format"
@@ -11,19 +14,41 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/platinasystems/weeb"
+	"github.com/platinasystems/weeb/html"
 )
 
 type config struct {
-	inFile       string
 	outFile      string
 	pkgName      string
 	urlPath      string
+	urlPrefix    string
 	noCompress   bool
 	noInlineData bool
+	cacheControl string
+	encodings    string
+	embed        bool
+	force        bool
+	check        bool
+	mtime        string
+	fingerprint  bool
+	contentType  string
+	integrity    string
+	// Name of embed.FS variable holding data files when embed is set.
+	fsVar string
+	// Directory for data files.
+	outDir string
+	// Input directories; checked with input files when deciding whether output is up to date.
+	dirs []string
+	// Data files to write by path.
+	files map[string][]byte
 }
 
 type hexWriter struct {
@@ -53,116 +78,426 @@ func (h *hexWriter) Close() error {
 	return nil
 }
 
-func main() {
-	c := &config{}
+// External compressor commands by content encoding; each writes compressed input file to stdout.
+var compressors = map[string][]string{
+	"br":   {"brotli", "-c", "-q", "11"},
+	"zstd": {"zstd", "-c", "-q", "-19"},
+}
 
-	flag.StringVar(&c.outFile, "o", "", "Output file (- for stdout)")
-	flag.StringVar(&c.pkgName, "package", "", "Package name for Go output.")
-	flag.StringVar(&c.urlPath, "url", "", "URL path to use for content.")
-	flag.BoolVar(&c.noCompress, "no-compress", false, "Disable compression.")
-	flag.BoolVar(&c.noInlineData, "no-inline-data", false, "Disable inline data; instead create and reference file.")
-	flag.Parse()
-	if flag.NArg() != 1 {
-		fmt.Fprintf(os.Stderr, "Missing file name\n")
-		flag.PrintDefaults()
-		os.Exit(1)
+func (c *config) encodingList() (encs []string) {
+	for _, enc := range strings.Split(c.encodings, ",") {
+		if enc = strings.TrimSpace(enc); len(enc) > 0 {
+			encs = append(encs, enc)
+		}
+	}
+	return
+}
+
+// Name of data file for asset (before any alternate encoding extension).
+func (c *config) dataFile(a *asset) string {
+	if !a.compress {
+		return a.name
+	}
+	return a.name + ".gz"
+}
+
+// Encodings of alternate content for asset.
+func (c *config) alternates(a *asset) []string {
+	if !a.compress {
+		return nil
+	}
+	return c.encodingList()
+}
+
+// Data files created for asset.
+func (c *config) dataFiles(a *asset) (fs []string) {
+	fs = append(fs, c.dataFile(a))
+	for _, enc := range c.alternates(a) {
+		fs = append(fs, a.name+"."+enc)
+	}
+	return
+}
+
+// Does file already have given contents?
+func unchanged(name string, b []byte) bool {
+	old, err := ioutil.ReadFile(name)
+	return err == nil && bytes.Equal(old, b)
+}
+
+// Write file unless it already has given contents so that unchanged files keep their modification times.
+func writeIfChanged(name string, b []byte) error {
+	if unchanged(name, b) {
+		return nil
+	}
+	return ioutil.WriteFile(name, b, 0666)
+}
+
+// Modification time for asset: SOURCE_DATE_EPOCH if set; otherwise as given by -mtime flag.
+func (c *config) modTime(a *asset) (t int64, ok bool) {
+	if e := os.Getenv("SOURCE_DATE_EPOCH"); len(e) > 0 {
+		t, err := strconv.ParseInt(e, 10, 64)
+		if err != nil {
+			log.Fatalf("SOURCE_DATE_EPOCH: %v", err)
+		}
+		return t, true
+	}
+	switch c.mtime {
+	case "file":
+		fi, err := os.Stat(a.inFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return fi.ModTime().Unix(), true
+	case "now":
+		return time.Now().Unix(), true
+	case "none":
+		// Clients revalidate using entity tag computed from content hash.
+		return 0, false
+	default:
+		log.Fatalf("unknown -mtime %s", c.mtime)
+	}
+	return
+}
+
+// Write content data inline, or to file in output directory which is referenced by path or embedded.
+func (c *config) data(w io.Writer, file string, b []byte) {
+	if !c.noInlineData {
+		fmt.Fprintf(w, "    Data: ")
+		hw := &hexWriter{w: w}
+		hw.Write(b)
+		hw.Close()
+		fmt.Fprintf(w, ",\n")
+		return
 	}
-	c.inFile = flag.Arg(0)
+	outPath := fmt.Sprintf("%s/%s", c.outDir, file)
+	c.files[outPath] = b
+	if len(c.fsVar) > 0 {
+		fmt.Fprintf(w, "    FS: %s,\n", c.fsVar)
+		fmt.Fprintf(w, "    FilePath: \"%s\",\n", file)
+	} else {
+		fmt.Fprintf(w, "    FilePath: \"%s\",\n", outPath)
+	}
+}
 
-	var err error
-	var file *os.File
-	file, err = os.Open(c.inFile)
+// Compress input file with external compressor and write alternate content with given encoding.
+func (c *config) alternate(w io.Writer, a *asset, enc string) {
+	args, ok := compressors[enc]
+	if !ok {
+		log.Fatalf("unknown encoding %s", enc)
+	}
+	b, err := exec.Command(args[0], append(args[1:], a.inFile)...).Output()
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("%s: %v", args[0], err)
 	}
+	fmt.Fprintf(w, "      {\n")
+	fmt.Fprintf(w, "        ContentEncoding: \"%s\",\n", enc)
+	c.data(w, a.name+"."+enc, b)
+	fmt.Fprintf(w, "      },\n")
+}
 
-	w := new(bytes.Buffer)
-	fmt.Fprintf(w, "// autogenerated: do not edit!\n")
-	fmt.Fprintf(w, "// generated from weebgen %s\n", c.inFile)
+// Insert hash of content into URL path before first extension (e.g. /js/foo.min.js => /js/foo.1a2b3c4d5e6f7a8b.min.js).
+func fingerprint(urlPath string, b []byte) string {
+	sum := sha256.Sum256(b)
+	h := hex.EncodeToString(sum[:8])
+	dir, base := path.Split(urlPath)
+	if i := strings.IndexByte(base, '.'); i > 0 {
+		return dir + base[:i] + "." + h + base[i:]
+	}
+	return dir + base + "." + h
+}
 
-	fmt.Fprintf(w, "\n// +build !js\n\n") // don't build for js/gopherjs compile
+// Subresource integrity digest of data with algorithm given by -integrity flag.
+func (c *config) integrityDigest(b []byte) string {
+	var sum []byte
+	switch c.integrity {
+	case "sha256":
+		s := sha256.Sum256(b)
+		sum = s[:]
+	case "sha384":
+		s := sha512.Sum384(b)
+		sum = s[:]
+	case "sha512":
+		s := sha512.Sum512(b)
+		sum = s[:]
+	default:
+		log.Fatalf("unknown -integrity %s", c.integrity)
+	}
+	return c.integrity + "-" + base64.StdEncoding.EncodeToString(sum)
+}
 
-	name := weeb.GoPackageNameForPath(c.inFile)
-	if len(c.pkgName) == 0 {
-		c.pkgName = name
+// Served form of asset: fingerprinted URL path (with -fingerprint) and integrity digest (with -integrity).
+func (c *config) served(a *asset) (s html.Asset) {
+	s.URL = a.urlPath
+	if c.fingerprint {
+		s.URL = fingerprint(a.urlPath, a.data)
 	}
-	if len(c.urlPath) == 0 {
-		c.urlPath = name
+	if len(c.integrity) > 0 {
+		s.Integrity = c.integrityDigest(a.data)
 	}
+	return
+}
 
-	fmt.Fprintf(w, "package %s\n", c.pkgName)
+// Write fields of weeb.Content literal for asset.
+func (c *config) content(w io.Writer, a *asset) {
+	b := a.data
+	s := c.served(a)
+	if c.fingerprint {
+		fmt.Fprintf(w, "    URLPath: \"%s\",\n", s.URL)
+		fmt.Fprintf(w, "    Name: \"%s\",\n", a.urlPath)
+	} else {
+		fmt.Fprintf(w, "    URLPath: \"%s\",\n", a.urlPath)
+	}
+	if t, ok := c.modTime(a); ok {
+		fmt.Fprintf(w, "    UnixTimeLastModified: %d, // %s\n", t, time.Unix(t, 0).UTC().String())
+	}
 
-	fmt.Fprintf(w, "import \"github.com/platinasystems/weeb\"\n")
-	fmt.Fprintf(w, "func init() {\n")
-	fmt.Fprintf(w, "  c := &weeb.Content{\n")
-	fmt.Fprintf(w, "    URLPath: \"%s\",\n", c.urlPath)
-	now := time.Now()
-	fmt.Fprintf(w, "    UnixTimeLastModified: %d, // %s\n", now.Unix(), now.UTC().String())
-
-	ct := "text/plain"
-	ext := filepath.Ext(c.inFile)
-	switch ext {
-	case ".js":
-		ct = "text/javascript"
-	case ".css":
-		ct = "text/css"
-	}
-	fmt.Fprintf(w, "    ContentType: \"%s\",\n", ct)
-
-	if !c.noCompress {
+	fmt.Fprintf(w, "    ContentType: \"%s\",\n", a.contentType)
+
+	if len(s.Integrity) > 0 {
+		fmt.Fprintf(w, "    Integrity: \"%s\",\n", s.Integrity)
+	}
+
+	if a.compress {
 		fmt.Fprintf(w, "    ContentEncoding: \"%s\",\n", "gzip")
 	}
 
-	r := bufio.NewReader(file)
+	cacheControl := c.cacheControl
+	if c.fingerprint && len(cacheControl) == 0 {
+		// Content at fingerprinted URL never changes.
+		cacheControl = "immutable"
+	}
+	switch cacheControl {
+	case "":
+	case "immutable":
+		fmt.Fprintf(w, "    CacheControl: weeb.CacheImmutable,\n")
+	default:
+		fmt.Fprintf(w, "    CacheControl: %q,\n", cacheControl)
+	}
+
+	if a.compress {
+		// Header has no name or modification time so output depends only on input.
+		var z bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&z, gzip.BestCompression)
+		zw.Write(b)
+		zw.Close()
+		b = z.Bytes()
+	}
+	c.data(w, c.dataFile(a), b)
+
+	if encs := c.alternates(a); len(encs) > 0 {
+		fmt.Fprintf(w, "    Alternates: []*weeb.Content{\n")
+		for _, enc := range encs {
+			c.alternate(w, a, enc)
+		}
+		fmt.Fprintf(w, "    },\n")
+	}
+}
 
-	var iw io.WriteCloser
-	var hw io.WriteCloser
+// Flags affecting output (in lexical order) followed by input arguments.
+func (c *config) args() (as []string) {
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "check", "f":
+			return
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() && f.Value.String() == "true" {
+			as = append(as, "-"+f.Name)
+		} else {
+			as = append(as, "-"+f.Name, f.Value.String())
+		}
+	})
+	return append(as, flag.Args()...)
+}
+
+// Manifest file for output file: Go source without content data built for all targets (unlike output file)
+// so that gopherjs clients resolve the same asset URLs and integrity digests as the server.
+func manifestFile(outFile string) string {
+	return strings.TrimSuffix(outFile, ".go") + "_manifest.go"
+}
 
-	dirFile := c.inFile
-	if c.outFile != "-" && c.outFile != "" {
-		dirFile = c.outFile
+// Source of manifest registering served form of assets by name.
+func (c *config) manifest(header string, as []*asset) []byte {
+	w := new(bytes.Buffer)
+	fmt.Fprintf(w, "%s\n", header)
+	fmt.Fprintf(w, "package %s\n", c.pkgName)
+	fmt.Fprintf(w, "import (\n\"github.com/platinasystems/weeb\"\n\"github.com/platinasystems/weeb/html\"\n)\n")
+	fmt.Fprintf(w, "func init() {\n")
+	fmt.Fprintf(w, "  weeb.RegisterAssets(html.AssetMap{\n")
+	for _, a := range as {
+		s := c.served(a)
+		fmt.Fprintf(w, "%q: {URL: %q, Integrity: %q},\n", a.urlPath, s.URL, s.Integrity)
 	}
-	outDir := filepath.Dir(dirFile)
-	outExt := ""
-	if !c.noCompress {
-		outExt = ".gz"
+	fmt.Fprintf(w, "})\n")
+	fmt.Fprintf(w, "}\n")
+	b, err := format.Source(w.Bytes())
+	if err != nil {
+		fmt.Printf("%s", w.Bytes())
+		panic(err)
 	}
-	outPath := ""
-	if c.noInlineData {
-		outPath = fmt.Sprintf("%s/%s%s", outDir, name, outExt)
-		hw, err = os.Create(outPath)
-		if err != nil {
-			panic(err)
+	return b
+}
+
+// Is argument a single file (rather than a directory or glob pattern)?
+func isFile(arg string) bool {
+	fi, err := os.Stat(arg)
+	return err == nil && !fi.IsDir() && !strings.ContainsAny(arg, "*?[")
+}
+
+const autogenerated = "// autogenerated: do not edit!\n"
+
+func main() {
+	c := &config{}
+
+	flag.StringVar(&c.outFile, "o", "", "Output file (- for stdout)")
+	flag.StringVar(&c.pkgName, "package", "", "Package name for Go output.")
+	flag.StringVar(&c.urlPath, "url", "", "URL path to use for content (single input file).")
+	flag.StringVar(&c.urlPrefix, "prefix", "/", "URL path prefix for content from directories, globs or multiple files.")
+	flag.BoolVar(&c.noCompress, "no-compress", false, "Disable compression.")
+	flag.BoolVar(&c.noInlineData, "no-inline-data", false, "Disable inline data; instead create and reference file.")
+	flag.BoolVar(&c.embed, "embed", false, "With -no-inline-data, embed created files in binary (go:embed) instead of reading them at run time.")
+	flag.StringVar(&c.encodings, "encodings", "", "Comma separated alternate encodings to generate with external compressors (br, zstd).")
+	flag.StringVar(&c.cacheControl, "cache-control", "", "Cache-Control header for content (e.g. immutable for fingerprinted URLs).")
+	flag.BoolVar(&c.force, "f", false, "Generate output even if it is newer than all inputs.")
+	flag.StringVar(&c.contentType, "type", "", "Content type for all inputs, or comma separated .EXT=TYPE overrides (default by extension or content).")
+	flag.StringVar(&c.integrity, "integrity", "", "Compute subresource integrity digest of content: sha256, sha384 or sha512.")
+	flag.BoolVar(&c.fingerprint, "fingerprint", false, "Insert hash of content into URL path; Script and Link nodes resolve original path (immutable caching by default).  With -fingerprint or -integrity a data-free OUT_manifest.go is also written for gopherjs clients.")
+	flag.BoolVar(&c.check, "check", false, "Do not write output; exit with status 1 if generated files are stale.")
+	flag.StringVar(&c.mtime, "mtime", "none", "Content modification time: none (rely on entity tags), file (input file time) or now.  Overridden by SOURCE_DATE_EPOCH.")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Missing file name\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	// A single input file is registered by its own init function; otherwise all inputs share one.
+	single := flag.NArg() == 1 && isFile(flag.Arg(0))
+
+	var (
+		as  []*asset
+		err error
+	)
+	if single {
+		inFile := flag.Arg(0)
+		name := weeb.GoPackageNameForPath(inFile)
+		if len(c.pkgName) == 0 {
+			c.pkgName = name
+		}
+		if len(c.urlPath) == 0 {
+			c.urlPath = name
+		}
+		as = []*asset{{inFile: inFile, urlPath: c.urlPath, name: name}}
+		dirFile := inFile
+		if c.outFile != "-" && c.outFile != "" {
+			dirFile = c.outFile
+		}
+		c.outDir = filepath.Dir(dirFile)
+		if c.outFile == "" {
+			c.outFile = fmt.Sprintf("%s/%s_weebgen.go", c.outDir, name)
 		}
 	} else {
-		hw = &hexWriter{w: w}
-		iw = hw
+		if len(c.urlPath) > 0 {
+			log.Fatal("-url requires a single input file; use -prefix")
+		}
+		if c.outFile == "" {
+			c.outFile = "assets_weebgen.go"
+		}
+		c.outDir = "."
+		if c.outFile != "-" {
+			c.outDir = filepath.Dir(c.outFile)
+		}
+		if as, err = c.assets(flag.Args()); err != nil {
+			log.Fatal(err)
+		}
+		if len(c.pkgName) == 0 {
+			// Set by go generate.
+			c.pkgName = os.Getenv("GOPACKAGE")
+		}
+		if len(c.pkgName) == 0 {
+			dir, _ := os.Getwd()
+			c.pkgName = weeb.GoPackageNameForPath(dir)
+		}
 	}
 
-	if !c.noCompress {
-		iw = gzip.NewWriter(hw)
+	for _, a := range as {
+		c.classify(a)
 	}
 
-	if iw != nil {
-		if !c.noInlineData {
-			fmt.Fprintf(w, "    Data: ")
-		} else {
-			fmt.Fprintf(w, "    FilePath: \"%s\"", outPath)
+	header := autogenerated
+	header += fmt.Sprintf("// generated from weebgen %s\n", strings.Join(c.args(), " "))
+	if e := os.Getenv("SOURCE_DATE_EPOCH"); len(e) > 0 {
+		// Modification times depend on environment as well as arguments.
+		header += fmt.Sprintf("// with SOURCE_DATE_EPOCH=%s\n", e)
+	}
+
+	// Manifest is only needed when served URLs or integrity differ from plain URL paths.
+	hasManifest := c.outFile != "-" && (c.fingerprint || len(c.integrity) > 0)
+
+	// Skip unchanged inputs.
+	if c.outFile != "-" && !c.force && !c.check {
+		inputs := append([]string(nil), c.dirs...)
+		if hasManifest {
+			inputs = append(inputs, manifestFile(c.outFile))
 		}
-		_, err := io.Copy(iw, r)
-		if err != nil {
-			panic(err)
+		for _, a := range as {
+			inputs = append(inputs, a.inFile)
+			if c.noInlineData {
+				for _, f := range c.dataFiles(a) {
+					inputs = append(inputs, filepath.Join(c.outDir, f))
+				}
+			}
 		}
-		iw.Close()
-		if iw != hw {
-			hw.Close()
+		if upToDate(c.outFile, header, inputs) {
+			return
 		}
-		fmt.Fprintf(w, ",\n")
 	}
 
-	fmt.Fprintf(w, "}\n") // ends &web.Content{
+	c.files = make(map[string][]byte)
+	w := new(bytes.Buffer)
+	fmt.Fprintf(w, "%s", header)
+
+	fmt.Fprintf(w, "\n// +build !js\n\n") // don't build for js/gopherjs compile
+
+	fmt.Fprintf(w, "package %s\n", c.pkgName)
+
+	if c.noInlineData && c.embed {
+		if single {
+			c.fsVar = as[0].name + "_fs"
+		} else {
+			base := "assets"
+			if c.outFile != "-" {
+				base = strings.TrimSuffix(filepath.Base(c.outFile), ".go")
+			}
+			c.fsVar = identifier(base) + "_fs"
+		}
+		var files []string
+		for _, a := range as {
+			files = append(files, c.dataFiles(a)...)
+		}
+		fmt.Fprintf(w, "import (\n\"embed\"\n\n\"github.com/platinasystems/weeb\"\n)\n")
+		fmt.Fprintf(w, "//go:embed %s\n", strings.Join(files, " "))
+		fmt.Fprintf(w, "var %s embed.FS\n", c.fsVar)
+	} else {
+		fmt.Fprintf(w, "import \"github.com/platinasystems/weeb\"\n")
+	}
 
-	fmt.Fprintf(w, "c.Register()\n")
+	fmt.Fprintf(w, "func init() {\n")
+	if single {
+		fmt.Fprintf(w, "  c := &weeb.Content{\n")
+		c.content(w, as[0])
+		fmt.Fprintf(w, "}\n") // ends &web.Content{
+		fmt.Fprintf(w, "c.Register()\n")
+	} else {
+		fmt.Fprintf(w, "  for _, c := range []*weeb.Content{\n")
+		for _, a := range as {
+			fmt.Fprintf(w, "{\n")
+			c.content(w, a)
+			fmt.Fprintf(w, "},\n")
+		}
+		fmt.Fprintf(w, "} {\n")
+		fmt.Fprintf(w, "c.Register()\n")
+		fmt.Fprintf(w, "}\n")
+	}
 	fmt.Fprintf(w, "}\n")
 
 	// gofmt result
@@ -173,11 +508,46 @@ func main() {
 		panic(err)
 	}
 
+	if hasManifest {
+		c.files[manifestFile(c.outFile)] = c.manifest(header, as)
+	}
+
+	if c.check {
+		if c.outFile != "-" {
+			c.files[c.outFile] = b
+		}
+		stale := false
+		for name, b := range c.files {
+			if !unchanged(name, b) {
+				fmt.Fprintf(os.Stderr, "%s: stale\n", name)
+				stale = true
+			}
+		}
+		if stale {
+			os.Exit(1)
+		}
+		return
+	}
+
+	for name, b := range c.files {
+		if err = writeIfChanged(name, b); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if c.outFile != "-" && !hasManifest {
+		// Remove manifest left by earlier run with -fingerprint or -integrity.
+		if mb, err := ioutil.ReadFile(manifestFile(c.outFile)); err == nil && strings.HasPrefix(string(mb), autogenerated) {
+			os.Remove(manifestFile(c.outFile))
+		}
+	}
 	if c.outFile != "-" {
-		if c.outFile == "" {
-			c.outFile = fmt.Sprintf("%s/%s_weebgen.go", outDir, name)
+		if unchanged(c.outFile, b) {
+			// Output is again newer than its inputs so later runs are skipped.
+			now := time.Now()
+			err = os.Chtimes(c.outFile, now, now)
+		} else {
+			err = ioutil.WriteFile(c.outFile, b, 0666)
 		}
-		err = ioutil.WriteFile(c.outFile, b, 0666)
 		if err != nil {
 			log.Fatalf("can't write output: %v\n", err)
 		}
@@ -0,0 +1,491 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test binary runs weebgen main when re-executed by run.
+func TestMain(m *testing.M) {
+	if os.Getenv("WEEBGEN_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// Run weebgen with arguments in directory and environment (e.g. SOURCE_DATE_EPOCH=1) added
+// to test environment.
+func weebgen(dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = []string{"WEEBGEN_TEST_MAIN=1"}
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, "SOURCE_DATE_EPOCH=") {
+			cmd.Env = append(cmd.Env, e)
+		}
+	}
+	cmd.Env = append(cmd.Env, env...)
+	return cmd.CombinedOutput()
+}
+
+func run(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if out, err := weebgen(dir, nil, args...); err != nil {
+		t.Fatalf("weebgen %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, s := range files {
+		fn := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fn), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fn, []byte(s), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+var urlPathRe = regexp.MustCompile(`URLPath:\s+"([^"]*)"`)
+
+// URL paths registered by generated file.
+func urlPaths(t *testing.T, fn string) (ps []string) {
+	t.Helper()
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range urlPathRe.FindAllStringSubmatch(string(b), -1) {
+		ps = append(ps, m[1])
+	}
+	return
+}
+
+// Set modification times of all files and directories under dir.
+func setTimes(t *testing.T, dir string, mtime time.Time) {
+	t.Helper()
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err == nil {
+			err = os.Chtimes(p, mtime, mtime)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func modTime(t *testing.T, fn string) time.Time {
+	t.Helper()
+	fi, err := os.Stat(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.ModTime()
+}
+
+// Output and data files written into input directory are not inputs of later runs.
+func TestDirIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"static/a.js":         "var a;",
+		"static/css/b.css":    "b {}",
+		"static/.hidden":      "x",
+		"static/c_weebgen.go": "package p",
+	})
+	out := filepath.Join(dir, "static", "assets_weebgen.go")
+	want := []string{"/s/a.js", "/s/css/b.css"}
+	for _, c := range []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"static"}, want},
+		{[]string{"-no-inline-data", "static"}, want},
+		{[]string{"-no-inline-data", "-no-compress", "static"}, want},
+		{[]string{"-no-inline-data", "static/*.js", "static/css"}, []string{"/s/a.js", "/s/b.css"}},
+	} {
+		args := append([]string{"-f", "-package", "p", "-prefix", "/s", "-o", "static/assets_weebgen.go"}, c.args...)
+		for i := 0; i < 2; i++ {
+			run(t, dir, args...)
+			if got := urlPaths(t, out); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("%v run %d: got %v want %v", c.args, i, got, c.want)
+			}
+		}
+	}
+	for _, name := range []string{"a_js", "a_js.gz", "css_b_css", "css_b_css.gz"} {
+		if _, err := os.Stat(filepath.Join(dir, "static", name)); err != nil {
+			t.Errorf("data file %s: %v", name, err)
+		}
+	}
+}
+
+// Output is only generated when inputs change (including files added to or removed from directories).
+func TestUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"static/a.js":      "var a;",
+		"static/css/b.css": "b {}",
+	})
+	out := filepath.Join(dir, "assets_weebgen.go")
+	args := []string{"-package", "p", "-prefix", "/s", "-no-inline-data", "static"}
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	run(t, dir, args...)
+	setTimes(t, dir, old)
+	run(t, dir, args...)
+	if !modTime(t, out).Equal(old) {
+		t.Error("unchanged inputs regenerated output")
+	}
+
+	// Different arguments.
+	run(t, dir, append([]string{"-cache-control", "immutable"}, args...)...)
+	if modTime(t, out).Equal(old) {
+		t.Error("output not regenerated for new arguments")
+	}
+	run(t, dir, args...)
+
+	// Forced.
+	setTimes(t, dir, old)
+	run(t, dir, append([]string{"-f"}, args...)...)
+	if modTime(t, out).Equal(old) {
+		t.Error("forced output not regenerated")
+	}
+
+	// Removed input.
+	setTimes(t, dir, old)
+	if err := os.Remove(filepath.Join(dir, "static", "css", "b.css")); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, args...)
+	if got, want := urlPaths(t, out), []string{"/s/a.js"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after remove: got %v want %v", got, want)
+	}
+
+	// Added input.
+	setTimes(t, dir, old)
+	writeFiles(t, dir, map[string]string{"static/d.js": "var d;"})
+	run(t, dir, args...)
+	if got, want := urlPaths(t, out), []string{"/s/a.js", "/s/d.js"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after add: got %v want %v", got, want)
+	}
+
+	// Removed data file.
+	setTimes(t, dir, old)
+	if err := os.Remove(filepath.Join(dir, "d_js.gz")); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, args...)
+	if _, err := os.Stat(filepath.Join(dir, "d_js.gz")); err != nil {
+		t.Error(err)
+	}
+}
+
+func readFile(t *testing.T, fn string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// Generated output is reproducible and -check passes right after generating.
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.js":             "var a;",
+		"static/b.css":     "b {}",
+		"static/img/c.svg": "<svg/>",
+	})
+	for _, c := range []struct {
+		out  string
+		args []string
+	}{
+		{"a_weebgen.go", []string{"a.js"}},
+		{"s_weebgen.go", []string{"-package", "p", "-prefix", "/s", "static"}},
+		{"s_weebgen.go", []string{"-package", "p", "-prefix", "/s", "-no-inline-data", "static"}},
+		{"s_weebgen.go", []string{"-package", "p", "-prefix", "/s", "-no-compress", "static"}},
+	} {
+		args := append([]string{"-o", c.out}, c.args...)
+		out := filepath.Join(dir, c.out)
+		run(t, dir, args...)
+		first := readFile(t, out)
+		run(t, dir, append([]string{"-check"}, args...)...)
+		run(t, dir, append([]string{"-f"}, args...)...)
+		if readFile(t, out) != first {
+			t.Errorf("%v: output differs when regenerated", args)
+		}
+		if strings.Contains(first, "-check") || strings.Contains(first, " -f ") {
+			t.Errorf("%v: header records -check or -f", args)
+		}
+
+		// Changed input is stale.
+		writeFiles(t, dir, map[string]string{"a.js": "var a2;", "static/b.css": "b { x: y }"})
+		if b, err := weebgen(dir, nil, append([]string{"-check"}, args...)...); err == nil {
+			t.Errorf("%v: -check passed with changed input", args)
+		} else if !strings.Contains(string(b), "stale") {
+			t.Errorf("%v: -check: %s", args, b)
+		}
+		if readFile(t, out) != first {
+			t.Errorf("%v: -check wrote output", args)
+		}
+		writeFiles(t, dir, map[string]string{"a.js": "var a;", "static/b.css": "b {}"})
+	}
+}
+
+var mtimeRe = regexp.MustCompile(`UnixTimeLastModified:\s+(\d+)`)
+
+func TestModTime(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.js": "var a;"})
+	mtime := time.Unix(1500000000, 0)
+	if err := os.Chtimes(filepath.Join(dir, "a.js"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	for _, c := range []struct {
+		env   []string
+		mtime string
+		want  int64
+	}{
+		// Default relies on entity tags.
+		{nil, "", 0},
+		{nil, "file", 1500000000},
+		{nil, "now", now},
+		{nil, "none", 0},
+		{[]string{"SOURCE_DATE_EPOCH=1600000000"}, "file", 1600000000},
+		{[]string{"SOURCE_DATE_EPOCH=1600000000"}, "none", 1600000000},
+	} {
+		args := []string{"-f", "-o", "-", "a.js"}
+		if len(c.mtime) > 0 {
+			args = append([]string{"-mtime", c.mtime}, args...)
+		}
+		b, err := weebgen(dir, c.env, args...)
+		if err != nil {
+			t.Fatalf("%v %s: %v\n%s", c.env, c.mtime, err, b)
+		}
+		var got int64
+		if m := mtimeRe.FindSubmatch(b); m != nil {
+			got, _ = strconv.ParseInt(string(m[1]), 10, 64)
+		}
+		if got != c.want && !(c.mtime == "now" && got >= now && got <= time.Now().Unix()) {
+			t.Errorf("%v %s: got %d want %d", c.env, c.mtime, got, c.want)
+		}
+	}
+	if b, err := weebgen(dir, nil, "-mtime", "x", "-o", "-", "a.js"); err == nil {
+		t.Errorf("unknown -mtime accepted: %s", b)
+	}
+}
+
+// Output is regenerated when SOURCE_DATE_EPOCH changes although inputs do not.
+func TestSourceDateEpoch(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.js": "var a;"})
+	out := filepath.Join(dir, "a_weebgen.go")
+	for _, c := range []struct {
+		env  []string
+		want string
+	}{
+		{[]string{"SOURCE_DATE_EPOCH=1600000000"}, "1600000000"},
+		{[]string{"SOURCE_DATE_EPOCH=1700000000"}, "1700000000"},
+		{nil, ""},
+	} {
+		if b, err := weebgen(dir, c.env, "-o", "a_weebgen.go", "a.js"); err != nil {
+			t.Fatalf("%v: %v\n%s", c.env, err, b)
+		}
+		got := ""
+		if m := mtimeRe.FindStringSubmatch(readFile(t, out)); m != nil {
+			got = m[1]
+		}
+		if got != c.want {
+			t.Errorf("%v: got modification time %q want %q", c.env, got, c.want)
+		}
+		if b, err := weebgen(dir, c.env, "-check", "-o", "a_weebgen.go", "a.js"); err != nil {
+			t.Errorf("%v: -check: %v\n%s", c.env, err, b)
+		}
+	}
+}
+
+// -check passes right after generating in each output mode.
+func TestCheckModes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.js":         "var a;",
+		"static/b.css": "b {}",
+		"static/c.js":  "var c;",
+	})
+	for _, args := range [][]string{
+		{"-o", "a_weebgen.go", "a.js"},
+		{"-o", "a_weebgen.go", "-no-inline-data", "a.js"},
+		{"-o", "a_weebgen.go", "-no-inline-data", "-embed", "a.js"},
+		{"-o", "a_weebgen.go", "-fingerprint", "a.js"},
+		{"-o", "a_weebgen.go", "-fingerprint", "-no-inline-data", "-embed", "a.js"},
+		{"-o", "a_weebgen.go", "-integrity", "sha256", "a.js"},
+		{"-package", "p", "-o", "static/s_weebgen.go", "static"},
+		{"-package", "p", "-o", "static/s_weebgen.go", "-no-inline-data", "static"},
+		{"-package", "p", "-o", "static/s_weebgen.go", "-no-inline-data", "-embed", "static"},
+		{"-package", "p", "-o", "static/s_weebgen.go", "-fingerprint", "static"},
+		{"-package", "p", "-o", "static/s_weebgen.go", "-fingerprint", "-no-inline-data", "static"},
+		{"-package", "p", "-o", "static/s_weebgen.go", "-integrity", "sha384", "-no-inline-data", "static"},
+	} {
+		for i := 0; i < 2; i++ {
+			run(t, dir, args...)
+			if b, err := weebgen(dir, nil, append([]string{"-check"}, args...)...); err != nil {
+				t.Errorf("%v run %d: -check: %v\n%s", args, i, err, b)
+			}
+		}
+	}
+}
+
+func TestFingerprintManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"static/b.css": "b {}",
+		"static/c.js":  "var c;",
+	})
+	args := []string{"-package", "p", "-prefix", "/s", "-o", "static/s_weebgen.go", "static"}
+	out := filepath.Join(dir, "static", "s_weebgen.go")
+	manifest := filepath.Join(dir, "static", "s_weebgen_manifest.go")
+
+	run(t, dir, append([]string{"-fingerprint"}, args...)...)
+	urls := urlPaths(t, out)
+	if len(urls) != 2 || !regexp.MustCompile(`^/s/b\.[0-9a-f]+\.css$`).MatchString(urls[0]) ||
+		!regexp.MustCompile(`^/s/c\.[0-9a-f]+\.js$`).MatchString(urls[1]) {
+		t.Fatalf("got URLs %v", urls)
+	}
+	m := readFile(t, manifest)
+	for _, s := range []string{
+		"// +build", "Data:",
+	} {
+		if strings.Contains(m, s) {
+			t.Errorf("manifest contains %s:\n%s", s, m)
+		}
+	}
+	for _, s := range []string{
+		"weeb.RegisterAssets(html.AssetMap{",
+		`"/s/b.css": {URL: "` + urls[0] + `", Integrity: ""}`,
+		`"/s/c.js":  {URL: "` + urls[1] + `", Integrity: ""}`,
+	} {
+		if !strings.Contains(m, s) {
+			t.Errorf("manifest missing %s:\n%s", s, m)
+		}
+	}
+
+	// Missing manifest is regenerated.
+	if err := os.Remove(manifest); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, append([]string{"-fingerprint"}, args...)...)
+	if readFile(t, manifest) != m {
+		t.Error("manifest not regenerated")
+	}
+
+	// Manifest is removed without -fingerprint.
+	run(t, dir, args...)
+	if _, err := os.Stat(manifest); !os.IsNotExist(err) {
+		t.Errorf("manifest not removed: %v", err)
+	}
+	if got, want := urlPaths(t, out), []string{"/s/b.css", "/s/c.js"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestIntegrity(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.js": "var a;"})
+	for _, c := range []struct {
+		alg, want string
+	}{
+		{"sha256", "sha256-" + digest(sha256.New(), "var a;")},
+		{"sha384", "sha384-" + digest(sha512.New384(), "var a;")},
+		{"sha512", "sha512-" + digest(sha512.New(), "var a;")},
+	} {
+		run(t, dir, "-o", "a_weebgen.go", "-url", "/a.js", "-integrity", c.alg, "a.js")
+		re := regexp.MustCompile(`Integrity:\s+"` + regexp.QuoteMeta(c.want) + `"`)
+		if got := readFile(t, filepath.Join(dir, "a_weebgen.go")); !re.MatchString(got) {
+			t.Errorf("%s: missing integrity %s:\n%s", c.alg, c.want, got)
+		}
+		m := readFile(t, filepath.Join(dir, "a_weebgen_manifest.go"))
+		if !strings.Contains(m, `"/a.js": {URL: "/a.js", Integrity: "`+c.want+`"}`) {
+			t.Errorf("%s: manifest missing integrity %s:\n%s", c.alg, c.want, m)
+		}
+	}
+	if b, err := weebgen(dir, nil, "-o", "a_weebgen.go", "-integrity", "md5", "a.js"); err == nil ||
+		!strings.Contains(string(b), "unknown -integrity md5") {
+		t.Errorf("expected unknown algorithm error: %v\n%s", err, b)
+	}
+}
+
+func digest(h hash.Hash, s string) string {
+	h.Write([]byte(s))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+var (
+	contentTypeRe     = regexp.MustCompile(`ContentType:\s+"([^"]*)"`)
+	contentEncodingRe = regexp.MustCompile(`ContentEncoding:\s+"gzip"`)
+)
+
+func TestContentType(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.js":      "var a;",
+		"b.CSS":     "b {}",
+		"c.png":     "\x89PNG\r\n\x1a\n",
+		"d.svg":     "<svg/>",
+		"e.unknown": "<!DOCTYPE html><html></html>",
+		"f.unknown": "\x1f\x8b\x08",
+		"g":         "plain text",
+		"h.woff2":   "wOF2",
+	})
+	for _, c := range []struct {
+		flags []string
+		file  string
+		want  string
+		gzip  bool
+	}{
+		{nil, "a.js", "text/javascript", true},
+		{nil, "b.CSS", "text/css", true},
+		{nil, "c.png", "image/png", false},
+		{nil, "d.svg", "image/svg+xml", true},
+		{nil, "h.woff2", "font/woff2", false},
+		// Unknown extensions are sniffed.
+		{nil, "e.unknown", "text/html; charset=utf-8", true},
+		{nil, "f.unknown", "application/x-gzip", false},
+		{nil, "g", "text/plain; charset=utf-8", true},
+		{[]string{"-no-compress"}, "a.js", "text/javascript", false},
+		{[]string{"-type", "application/x-custom"}, "a.js", "application/x-custom", true},
+		{[]string{"-type", "image/x-custom"}, "a.js", "image/x-custom", false},
+		{[]string{"-type", ".js=application/javascript,.png=image/x-png"}, "a.js", "application/javascript", true},
+		{[]string{"-type", ".JS=application/javascript"}, "a.js", "application/javascript", true},
+		{[]string{"-type", ".js=application/javascript"}, "b.CSS", "text/css", true},
+	} {
+		args := append(append([]string{}, c.flags...), "-o", "-", c.file)
+		b, err := weebgen(dir, nil, args...)
+		if err != nil {
+			t.Fatalf("%v: %v\n%s", args, err, b)
+		}
+		var got string
+		if m := contentTypeRe.FindSubmatch(b); m != nil {
+			got = string(m[1])
+		}
+		if gzip := contentEncodingRe.Match(b); got != c.want || gzip != c.gzip {
+			t.Errorf("%v: got %q gzip %v want %q gzip %v", args, got, gzip, c.want, c.gzip)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/platinasystems/weeb"
+)
+
+// Sniffed types whose formats are already compressed.
+func isCompressedType(t string) bool {
+	t = strings.TrimSpace(strings.SplitN(t, ";", 2)[0])
+	switch {
+	case t == "image/svg+xml", t == "image/bmp", t == "image/x-icon", t == "image/vnd.microsoft.icon":
+		return false
+	case strings.HasPrefix(t, "image/"), strings.HasPrefix(t, "video/"), strings.HasPrefix(t, "audio/"):
+		return t != "audio/wave"
+	case t == "font/woff", t == "font/woff2", t == "application/zip", t == "application/gzip", t == "application/x-gzip":
+		return true
+	}
+	return false
+}
+
+// Type given by -type flag for asset: either a single type for all inputs or
+// comma separated .EXT=TYPE overrides.
+func (c *config) typeOverride(a *asset) (t string, ok bool) {
+	if len(c.contentType) == 0 {
+		return
+	}
+	if !strings.HasPrefix(c.contentType, ".") {
+		return c.contentType, true
+	}
+	ext := strings.ToLower(filepath.Ext(a.inFile))
+	for _, x := range strings.Split(c.contentType, ",") {
+		if i := strings.IndexByte(x, '='); i > 0 && strings.ToLower(strings.TrimSpace(x[:i])) == ext {
+			return strings.TrimSpace(x[i+1:]), true
+		}
+	}
+	return
+}
+
+// Read asset and set its content type (by -type, extension or sniffing content) and whether to compress it.
+func (c *config) classify(a *asset) {
+	var err error
+	if a.data, err = ioutil.ReadFile(a.inFile); err != nil {
+		log.Fatal(err)
+	}
+	if t, ok := c.typeOverride(a); ok {
+		a.contentType = t
+		a.compress = !isCompressedType(t)
+	} else if t, compressed := weeb.TypeByExtension(filepath.Ext(a.inFile)); len(t) != 0 {
+		a.contentType = t
+		a.compress = !compressed
+	} else {
+		a.contentType = http.DetectContentType(a.data)
+		a.compress = !isCompressedType(a.contentType)
+	}
+	if c.noCompress {
+		a.compress = false
+	}
+}